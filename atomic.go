@@ -0,0 +1,161 @@
+package urlrouter
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SwapFunc is the type of the function called by AtomicRouter after a routing table has been swapped.
+// old is nil when the first routing table has been built.
+type SwapFunc func(old, new URLRouter, generation uint64)
+
+// AtomicRouter is a URLRouter that holds a built URLRouter and swaps it for a new one atomically.
+// Lookup can be called concurrently with Rebuild. It never waits for Rebuild, and it uses either the old or the new
+// routing table as a whole.
+type AtomicRouter struct {
+	router Router
+
+	// Current *snapshot.
+	current atomic.Value
+
+	// mu serializes the swaps, and guards hooks, swaps and notifying.
+	mu    sync.Mutex
+	hooks []SwapFunc
+
+	// Swaps whose hooks haven't been called yet, in the order of the generations.
+	swaps []swap
+
+	// Whether a Rebuild is calling the hooks of swaps.
+	notifying bool
+}
+
+// snapshot represents a built URLRouter and its generation.
+type snapshot struct {
+	router     URLRouter
+	generation uint64
+}
+
+// swap represents a swap of the routing tables and the hooks that are called for it.
+type swap struct {
+	old, new *snapshot
+	hooks    []SwapFunc
+}
+
+// NewAtomicRouter returns a new AtomicRouter that builds URLRouters by router.
+// router can be any Router such as the one returned by WithOptions.
+func NewAtomicRouter(router Router) *AtomicRouter {
+	ar := &AtomicRouter{router: router}
+	ar.current.Store(&snapshot{})
+	return ar
+}
+
+// Lookup implements the URLRouter.
+// It looks up path by the current routing table. If no routing table has been built, data will be nil.
+func (ar *AtomicRouter) Lookup(path string) (data interface{}, params []Param) {
+	router := ar.load().router
+	if router == nil {
+		return nil, nil
+	}
+	return router.Lookup(path)
+}
+
+// LookupInto implements the BufferedLookuper.
+func (ar *AtomicRouter) LookupInto(path string, dst []Param) (data interface{}, params []Param) {
+	router := ar.load().router
+	if router == nil {
+		return nil, dst[:0]
+	}
+	return LookupInto(router, path, dst)
+}
+
+// LookupTrailingSlash implements the TrailingSlashLookuper.
+func (ar *AtomicRouter) LookupTrailingSlash(path string) (data interface{}, params []Param, fixed string) {
+	router := ar.load().router
+	if router == nil {
+		return nil, nil, ""
+	}
+	return LookupTrailingSlash(router, path)
+}
+
+// Build implements the URLRouter. It is the same as Rebuild.
+func (ar *AtomicRouter) Build(records []Record) error {
+	return ar.Rebuild(records)
+}
+
+// Rebuild builds a new URLRouter from records, and swaps the current routing table for it.
+// The hooks registered by OnSwap are called in order after swapping.
+// If building fails, the current routing table is kept and the error is returned.
+// Concurrent calls of Rebuild swap the routing tables one by one, and the hooks are called in the order of the
+// generations. The hooks aren't called under a lock, so they can call Rebuild and OnSwap. If the hooks of another
+// swap are being called, the hooks of this swap are called by that Rebuild after them, and Rebuild returns
+// without waiting for them.
+func (ar *AtomicRouter) Rebuild(records []Record) error {
+	router := ar.router.New()
+	if err := router.Build(records); err != nil {
+		return err
+	}
+	ar.mu.Lock()
+	old := ar.load()
+	current := &snapshot{router: router, generation: old.generation + 1}
+	ar.current.Store(current)
+	ar.swaps = append(ar.swaps, swap{old: old, new: current, hooks: ar.hooks[:len(ar.hooks):len(ar.hooks)]})
+	if ar.notifying {
+		ar.mu.Unlock()
+		return nil
+	}
+	ar.notifying = true
+	ar.mu.Unlock()
+	ar.notify()
+	return nil
+}
+
+// notify calls the hooks of the swaps until no swaps are left.
+func (ar *AtomicRouter) notify() {
+	done := false
+	defer func() {
+		// the next Rebuild calls the hooks of the rest of the swaps even if a hook panics.
+		if !done {
+			ar.mu.Lock()
+			ar.notifying = false
+			ar.mu.Unlock()
+		}
+	}()
+	for {
+		ar.mu.Lock()
+		if len(ar.swaps) == 0 {
+			ar.notifying, done = false, true
+			ar.mu.Unlock()
+			return
+		}
+		s := ar.swaps[0]
+		ar.swaps = ar.swaps[1:]
+		ar.mu.Unlock()
+		for _, hook := range s.hooks {
+			hook(s.old.router, s.new.router, s.new.generation)
+		}
+	}
+}
+
+// OnSwap registers fn as a hook that is called after the routing table has been swapped by Rebuild.
+func (ar *AtomicRouter) OnSwap(fn SwapFunc) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	ar.hooks = append(ar.hooks, fn)
+}
+
+// Current returns the current URLRouter and its generation.
+// The generation is incremented by every successful Rebuild, and it is 0 before the first one.
+// The returned URLRouter is never modified by AtomicRouter, so it can be used after the next swap.
+func (ar *AtomicRouter) Current() (router URLRouter, generation uint64) {
+	s := ar.load()
+	return s.router, s.generation
+}
+
+// Generation returns the generation of the current routing table.
+func (ar *AtomicRouter) Generation() uint64 {
+	return ar.load().generation
+}
+
+func (ar *AtomicRouter) load() *snapshot {
+	return ar.current.Load().(*snapshot)
+}
@@ -0,0 +1,203 @@
+package urlrouter
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// switchRouter is a Router for testing whose URLRouters fail to build if fail is true.
+type switchRouter struct {
+	fail bool
+}
+
+func (r *switchRouter) New() URLRouter {
+	return &switchURLRouter{fail: r.fail}
+}
+
+type switchURLRouter struct {
+	staticURLRouter
+	fail bool
+}
+
+func (r *switchURLRouter) Build(records []Record) error {
+	if r.fail {
+		return errors.New("build failed")
+	}
+	return r.staticURLRouter.Build(records)
+}
+
+func Test_NewAtomicRouter(t *testing.T) {
+	ar := NewAtomicRouter(&staticRouter{})
+	router, generation := ar.Current()
+	var actual, expected interface{} = []interface{}{router, generation}, []interface{}{nil, uint64(0)}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	data, params := ar.Lookup("/")
+	actual, expected = []interface{}{data, params}, []interface{}{nil, []Param(nil)}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_AtomicRouter_Rebuild(t *testing.T) {
+	sr := &switchRouter{}
+	ar := NewAtomicRouter(sr)
+	type swap struct {
+		old, new   URLRouter
+		generation uint64
+	}
+	var swaps []swap
+	ar.OnSwap(func(old, new URLRouter, generation uint64) {
+		swaps = append(swaps, swap{old, new, generation})
+	})
+
+	if err := ar.Build([]Record{NewRecord("/", "root1")}); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := ar.Current()
+	if err := ar.Rebuild([]Record{NewRecord("/", "root2")}); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := ar.Current()
+	var actual, expected interface{} = swaps, []swap{{nil, first, 1}, {first, second, 2}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	data, _ := ar.Lookup("/")
+	actual, expected = data, "root2"
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	data, _ = first.Lookup("/")
+	actual, expected = data, "root1"
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	sr.fail = true
+	if err := ar.Rebuild([]Record{NewRecord("/", "root3")}); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+	data, _ = ar.Lookup("/")
+	actual, expected = []interface{}{data, ar.Generation(), len(swaps)}, []interface{}{"root2", uint64(2), 2}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_AtomicRouter_Rebuild_inHook(t *testing.T) {
+	ar := NewAtomicRouter(&staticRouter{})
+	var generations []uint64
+	ar.OnSwap(func(old, new URLRouter, generation uint64) {
+		generations = append(generations, generation)
+		if generation == 1 {
+			// the hooks can rebuild and register the hooks without a deadlock.
+			if err := ar.Rebuild([]Record{NewRecord("/", "root2")}); err != nil {
+				t.Error(err)
+			}
+			ar.OnSwap(func(old, new URLRouter, generation uint64) {
+				generations = append(generations, generation*10)
+			})
+		}
+	})
+	if err := ar.Rebuild([]Record{NewRecord("/", "root1")}); err != nil {
+		t.Fatal(err)
+	}
+	// the hooks of the second swap are called after the hooks of the first swap, and the hook that is registered
+	// after the second swap isn't called for it.
+	var actual, expected interface{} = generations, []uint64{1, 2}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	data, _ := ar.Lookup("/")
+	actual, expected = data, "root2"
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	if err := ar.Rebuild([]Record{NewRecord("/", "root3")}); err != nil {
+		t.Fatal(err)
+	}
+	actual, expected = generations, []uint64{1, 2, 3, 30}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_AtomicRouter_concurrentRebuild(t *testing.T) {
+	ar := NewAtomicRouter(&staticRouter{})
+	var generations []uint64
+	ar.OnSwap(func(old, new URLRouter, generation uint64) {
+		generations = append(generations, generation)
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := ar.Rebuild([]Record{NewRecord("/", j)}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// the hooks of all the swaps have been called in the order of the generations when all Rebuilds returned.
+	expected := make([]uint64, 8*50)
+	for i := range expected {
+		expected[i] = uint64(i + 1)
+	}
+	if !reflect.DeepEqual(generations, expected) {
+		t.Errorf("Expect %v, but %v", expected, generations)
+	}
+}
+
+func Test_AtomicRouter_concurrentLookup(t *testing.T) {
+	ar := NewAtomicRouter(&staticRouter{})
+	if err := ar.Build([]Record{NewRecord("/", 0), NewRecord("/user", 0)}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// both routes of a routing table have the same value, so they never differ in a snapshot.
+				router, _ := ar.Current()
+				root, _ := router.Lookup("/")
+				user, _ := router.Lookup("/user")
+				if root != user {
+					t.Errorf("Expect %v, but %v", root, user)
+					return
+				}
+				if data, _ := ar.Lookup("/"); data == nil {
+					t.Errorf("Expect non-nil, but nil")
+					return
+				}
+			}
+		}()
+	}
+	for i := 1; i <= 100; i++ {
+		if err := ar.Rebuild([]Record{NewRecord("/", i), NewRecord("/user", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+	var actual, expected interface{} = ar.Generation(), uint64(101)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
@@ -0,0 +1,739 @@
+// Code generated by urlrouter-gen from constraints.txt. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+
+	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/match"
+)
+
+// ConstraintsRouter is a URLRouter that the keys of constraints.txt are compiled into.
+// Build binds the values of records to the compiled keys, and the keys that aren't built never match.
+type ConstraintsRouter struct {
+	// Index of the key plus 1 that is built for each leaf, or 0 if the leaf isn't built.
+	routes [19]int
+
+	values      [19]interface{}
+	priorities  [19]int
+	maxPriority int
+	constraints [5]*urlrouter.Constraint
+}
+
+var _ urlrouter.URLRouter = (*ConstraintsRouter)(nil)
+
+// NewConstraintsRouter returns a new ConstraintsRouter.
+func NewConstraintsRouter() *ConstraintsRouter {
+	return &ConstraintsRouter{}
+}
+
+// Lookup returns result data of lookup from the routing table by given path.
+func (r *ConstraintsRouter) Lookup(path string) (data interface{}, params []urlrouter.Param) {
+	return r.LookupInto(path, nil)
+}
+
+// LookupInto is the same as Lookup, but path parameters are appended to dst[:0].
+func (r *ConstraintsRouter) LookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	m := match.New[int](r.maxPriority, urlrouter.Options{})
+	r.state0(path, dst[:0], &m)
+	leaf, matched, found := m.Result()
+	if !found {
+		return nil, dst[:0]
+	}
+	names := constraintsRouterParamNames[r.routes[leaf]-1]
+	params = append(dst[:0], matched...)
+	for i := range params {
+		params[i].Name = names[i]
+	}
+	return r.values[leaf], params
+}
+
+// Build binds the values of records to the compiled keys.
+// Optional segments of keys are expanded by urlrouter.ExpandRecords, and all of the expanded keys must have been
+// compiled. If the records have the same key, the last one is built.
+func (r *ConstraintsRouter) Build(records []urlrouter.Record) error {
+	if _, err := urlrouter.ExpandRecords(records); err != nil {
+		return err
+	}
+	var built ConstraintsRouter
+	var errs urlrouter.Errors
+	for i, record := range records {
+		keys, _ := urlrouter.ExpandOptional(record.Key)
+		for _, key := range keys {
+			k := constraintsRouterKeyIndex(key)
+			if k < 0 {
+				errs = append(errs, fmt.Errorf("record %d '%v': key isn't compiled into ConstraintsRouter", i, key))
+				continue
+			}
+			leaf := constraintsRouterLeaves[k]
+			built.routes[leaf], built.values[leaf], built.priorities[leaf] = k+1, record.Value, record.Priority
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	for i, spec := range constraintsRouterConstraints {
+		c, err := urlrouter.NewConstraint(spec)
+		if err != nil {
+			return err
+		}
+		built.constraints[i] = c
+	}
+	built.maxPriority = urlrouter.MaxPriority(records)
+	*r = built
+	return nil
+}
+
+// Walk implements the urlrouter.Walker.
+// The routes are walked in the order of the compiled tree.
+func (r *ConstraintsRouter) Walk(fn func(key string, value interface{}) error) error {
+	for leaf, route := range r.routes {
+		if route == 0 {
+			continue
+		}
+		if err := fn(constraintsRouterKeys[route-1], r.values[leaf]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match passes the leaf to m if it's built. It reports whether the lookup should be stopped.
+func (r *ConstraintsRouter) match(leaf int, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return r.routes[leaf] != 0 && m.Match(leaf, r.priorities[leaf], params)
+}
+
+// constraintsRouterKeyIndex returns an index of key in constraintsRouterKeys, or -1 if key isn't compiled.
+func constraintsRouterKeyIndex(key string) int {
+	switch key {
+	case "/user/:id<int>":
+		return 0
+	case "/user/:name":
+		return 1
+	case "/file/:name([a-z]+).:ext":
+		return 2
+	case "/file/:id<int>.:ext":
+		return 3
+	case "/file/*path":
+		return 4
+	case "/color/:hex([0-9a-f]{6})":
+		return 5
+	case "/date/:year([0-9]{4})/:month<uint>":
+		return 6
+	case "/post/:id<int>/edit":
+		return 7
+	case "/post/:name/view":
+		return 8
+	case "/":
+		return 9
+	case "/path/to/route":
+		return 10
+	case "/path/to/:param":
+		return 11
+	case "/user/:id<int>/:name":
+		return 12
+	case "/files/:name.:ext":
+		return 13
+	case "/static/*filepath":
+		return 14
+	case "/users/:id":
+		return 15
+	case "/users":
+		return 16
+	case "/:lang/help":
+		return 17
+	case "/static/*filepath/edit":
+		return 18
+	}
+	return -1
+}
+
+// constraintsRouterKeys are the compiled keys.
+var constraintsRouterKeys = [...]string{
+	"/user/:id<int>",
+	"/user/:name",
+	"/file/:name([a-z]+).:ext",
+	"/file/:id<int>.:ext",
+	"/file/*path",
+	"/color/:hex([0-9a-f]{6})",
+	"/date/:year([0-9]{4})/:month<uint>",
+	"/post/:id<int>/edit",
+	"/post/:name/view",
+	"/",
+	"/path/to/route",
+	"/path/to/:param",
+	"/user/:id<int>/:name",
+	"/files/:name.:ext",
+	"/static/*filepath",
+	"/users/:id",
+	"/users",
+	"/:lang/help",
+	"/static/*filepath/edit",
+}
+
+// constraintsRouterParamNames are the names of path parameters of the keys.
+var constraintsRouterParamNames = [...][]string{
+	{"id"},
+	{"name"},
+	{"name", "ext"},
+	{"id", "ext"},
+	{"path"},
+	{"hex"},
+	{"year", "month"},
+	{"id"},
+	{"name"},
+	nil,
+	nil,
+	{"param"},
+	{"id", "name"},
+	{"name", "ext"},
+	{"filepath"},
+	{"id"},
+	nil,
+	{"lang"},
+	{"filepath"},
+}
+
+// constraintsRouterLeaves are the indexes of the leaves of the keys.
+var constraintsRouterLeaves = [...]int{13, 15, 3, 4, 5, 1, 2, 9, 10, 0, 7, 8, 14, 6, 11, 17, 16, 18, 12}
+
+// constraintsRouterConstraints are the constraints of path parameters.
+var constraintsRouterConstraints = [...]string{
+	"([0-9a-f]{6})",
+	"([0-9]{4})",
+	"<uint>",
+	"([a-z]+)",
+	"<int>",
+}
+
+// constraintsRouterNoDelimiter is the delimiter function of the nodes that have no static children.
+func constraintsRouterNoDelimiter(c byte) bool {
+	return false
+}
+
+func (r *ConstraintsRouter) state0(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state1(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state1(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(0, params, m)
+	}
+	switch path[0] {
+	case 'c':
+		if len(path) >= 6 && path[:6] == "color/" && r.state2(path[6:], params, m) {
+			return true
+		}
+	case 'd':
+		if len(path) >= 5 && path[:5] == "date/" && r.state4(path[5:], params, m) {
+			return true
+		}
+	case 'f':
+		if len(path) >= 4 && path[:4] == "file" && r.state8(path[4:], params, m) {
+			return true
+		}
+	case 'p':
+		if r.state21(path[1:], params, m) {
+			return true
+		}
+	case 's':
+		if len(path) >= 7 && path[:7] == "static/" && r.state30(path[7:], params, m) {
+			return true
+		}
+	case 'u':
+		if len(path) >= 4 && path[:4] == "user" && r.state33(path[4:], params, m) {
+			return true
+		}
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterDelimiter0) {
+		if r.state42(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state2(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if c := r.constraints[0]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state3(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state3(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(1, params, m)
+}
+
+func (r *ConstraintsRouter) state4(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterDelimiter0) {
+		if c := r.constraints[1]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state5(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state5(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state6(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state6(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if c := r.constraints[2]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state7(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state7(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(2, params, m)
+}
+
+func (r *ConstraintsRouter) state8(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state9(path[1:], params, m) {
+			return true
+		}
+	case 's':
+		if len(path) >= 2 && path[:2] == "s/" && r.state17(path[2:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state9(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterDelimiter1) {
+		if c := r.constraints[3]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state10(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterDelimiter1) {
+		if c := r.constraints[4]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state13(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return r.match(5, append(params, urlrouter.Param{Value: path}), m)
+}
+
+func (r *ConstraintsRouter) state10(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '.':
+		if r.state11(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state11(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if r.state12(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state12(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(3, params, m)
+}
+
+func (r *ConstraintsRouter) state13(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '.':
+		if r.state14(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state14(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if r.state15(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state15(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(4, params, m)
+}
+
+func (r *ConstraintsRouter) state17(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterDelimiter1) {
+		if r.state18(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state18(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '.':
+		if r.state19(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state19(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if r.state20(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state20(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(6, params, m)
+}
+
+func (r *ConstraintsRouter) state21(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'a':
+		if len(path) >= 7 && path[:7] == "ath/to/" && r.state22(path[7:], params, m) {
+			return true
+		}
+	case 'o':
+		if len(path) >= 4 && path[:4] == "ost/" && r.state25(path[4:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state22(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'r':
+		if len(path) >= 5 && path[:5] == "route" && r.state23(path[5:], params, m) {
+			return true
+		}
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if r.state24(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state23(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(7, params, m)
+}
+
+func (r *ConstraintsRouter) state24(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(8, params, m)
+}
+
+func (r *ConstraintsRouter) state25(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterDelimiter0) {
+		if c := r.constraints[4]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state26(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterDelimiter0) {
+		if r.state28(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state26(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if len(path) >= 5 && path[:5] == "/edit" && r.state27(path[5:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state27(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(9, params, m)
+}
+
+func (r *ConstraintsRouter) state28(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if len(path) >= 5 && path[:5] == "/view" && r.state29(path[5:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state29(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(10, params, m)
+}
+
+func (r *ConstraintsRouter) state30(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.WildcardEnds(buf[:0], path, 0, false, false, constraintsRouterDelimiter0) {
+		if r.state31(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return r.match(11, append(params, urlrouter.Param{Value: path}), m)
+}
+
+func (r *ConstraintsRouter) state31(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	switch path[0] {
+	case '/':
+		if len(path) >= 5 && path[:5] == "/edit" && r.state32(path[5:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state32(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(12, params, m)
+}
+
+func (r *ConstraintsRouter) state33(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state34(path[1:], params, m) {
+			return true
+		}
+	case 's':
+		if r.state39(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state34(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterDelimiter0) {
+		if c := r.constraints[4]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state35(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if r.state38(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state35(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(13, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if r.state36(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state36(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if r.state37(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state37(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(14, params, m)
+}
+
+func (r *ConstraintsRouter) state38(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(15, params, m)
+}
+
+func (r *ConstraintsRouter) state39(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(16, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if r.state40(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state40(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, constraintsRouterNoDelimiter) {
+		if r.state41(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state41(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(17, params, m)
+}
+
+func (r *ConstraintsRouter) state42(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if len(path) >= 5 && path[:5] == "/help" && r.state43(path[5:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConstraintsRouter) state43(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(18, params, m)
+}
+
+func constraintsRouterDelimiter0(c byte) bool {
+	switch c {
+	case '/':
+		return true
+	}
+	return false
+}
+
+func constraintsRouterDelimiter1(c byte) bool {
+	switch c {
+	case '.':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,7 @@
+// Package example has the URLRouters generated by urlrouter-gen from routes.txt and constraints.txt.
+// They have the keys of the tests of testutil, and router_test.go runs them against the generated URLRouters.
+// The keys of the tests that conflict with each other are compiled into the different URLRouters.
+package example
+
+//go:generate go run .. -o router.go routes.txt
+//go:generate go run .. -o constraints_router.go -type ConstraintsRouter constraints.txt
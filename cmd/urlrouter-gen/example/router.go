@@ -0,0 +1,1356 @@
+// Code generated by urlrouter-gen from routes.txt. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+
+	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/match"
+)
+
+// Router is a URLRouter that the keys of routes.txt are compiled into.
+// Build binds the values of records to the compiled keys, and the keys that aren't built never match.
+type Router struct {
+	// Index of the key plus 1 that is built for each leaf, or 0 if the leaf isn't built.
+	routes [46]int
+
+	values      [46]interface{}
+	priorities  [46]int
+	maxPriority int
+	constraints [1]*urlrouter.Constraint
+}
+
+var _ urlrouter.URLRouter = (*Router)(nil)
+
+// NewRouter returns a new Router.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Lookup returns result data of lookup from the routing table by given path.
+func (r *Router) Lookup(path string) (data interface{}, params []urlrouter.Param) {
+	return r.LookupInto(path, nil)
+}
+
+// LookupInto is the same as Lookup, but path parameters are appended to dst[:0].
+func (r *Router) LookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	m := match.New[int](r.maxPriority, urlrouter.Options{})
+	r.state0(path, dst[:0], &m)
+	leaf, matched, found := m.Result()
+	if !found {
+		return nil, dst[:0]
+	}
+	names := routerParamNames[r.routes[leaf]-1]
+	params = append(dst[:0], matched...)
+	for i := range params {
+		params[i].Name = names[i]
+	}
+	return r.values[leaf], params
+}
+
+// Build binds the values of records to the compiled keys.
+// Optional segments of keys are expanded by urlrouter.ExpandRecords, and all of the expanded keys must have been
+// compiled. If the records have the same key, the last one is built.
+func (r *Router) Build(records []urlrouter.Record) error {
+	if _, err := urlrouter.ExpandRecords(records); err != nil {
+		return err
+	}
+	var built Router
+	var errs urlrouter.Errors
+	for i, record := range records {
+		keys, _ := urlrouter.ExpandOptional(record.Key)
+		for _, key := range keys {
+			k := routerKeyIndex(key)
+			if k < 0 {
+				errs = append(errs, fmt.Errorf("record %d '%v': key isn't compiled into Router", i, key))
+				continue
+			}
+			leaf := routerLeaves[k]
+			built.routes[leaf], built.values[leaf], built.priorities[leaf] = k+1, record.Value, record.Priority
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	for i, spec := range routerConstraints {
+		c, err := urlrouter.NewConstraint(spec)
+		if err != nil {
+			return err
+		}
+		built.constraints[i] = c
+	}
+	built.maxPriority = urlrouter.MaxPriority(records)
+	*r = built
+	return nil
+}
+
+// Walk implements the urlrouter.Walker.
+// The routes are walked in the order of the compiled tree.
+func (r *Router) Walk(fn func(key string, value interface{}) error) error {
+	for leaf, route := range r.routes {
+		if route == 0 {
+			continue
+		}
+		if err := fn(routerKeys[route-1], r.values[leaf]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match passes the leaf to m if it's built. It reports whether the lookup should be stopped.
+func (r *Router) match(leaf int, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return r.routes[leaf] != 0 && m.Match(leaf, r.priorities[leaf], params)
+}
+
+// routerKeyIndex returns an index of key in routerKeys, or -1 if key isn't compiled.
+func routerKeyIndex(key string) int {
+	switch key {
+	case "/":
+		return 0
+	case "/path/to/route":
+		return 1
+	case "/path/to/other":
+		return 2
+	case "/path/to/route/a":
+		return 3
+	case "/path/to/:param":
+		return 4
+	case "/path/to/wildcard/*routepath":
+		return 5
+	case "/path/to/:param1/:param2":
+		return 6
+	case "/path/to/:param1/sep/:param2":
+		return 7
+	case "/:year/:month/:day":
+		return 8
+	case "/user/:id":
+		return 9
+	case "/a/to/b/:param/*routepath":
+		return 10
+	case "/:b":
+		return 11
+	case "/*wildcard":
+		return 12
+	case "/v:version/x":
+		return 13
+	case "/range/:from-:to":
+		return 14
+	case "/img_:id.png":
+		return 15
+	case "/files/:name.:ext":
+		return 16
+	case "/range/:from-:to<int>":
+		return 17
+	case "/:lang-:region/help":
+		return 18
+	case "/page/:n<int>px":
+		return 19
+	case "/tag/:name":
+		return 20
+	case "/tag/:name<int>-x":
+		return 21
+	case "/t/:a-:b":
+		return 22
+	case "/t/:a~:b":
+		return 23
+	case "/files/*path":
+		return 24
+	case "/files/:name":
+		return 25
+	case "/files/:id<int>":
+		return 26
+	case "/files/new":
+		return 27
+	case "/files/:name/raw":
+		return 28
+	case "/:section/list":
+		return 29
+	case "/docs/:page":
+		return 30
+	case "/admin/*path":
+		return 31
+	case "/admin/login":
+		return 32
+	case "/:lang/help":
+		return 33
+	case "/help/:topic":
+		return 34
+	case "/archive/:year<int>/:month<int>":
+		return 35
+	case "/archive/:year<int>":
+		return 36
+	case "/archive":
+		return 37
+	case "/users/:id":
+		return 38
+	case "/users":
+		return 39
+	case "/users/:id/edit":
+		return 40
+	case "/users/edit":
+		return 41
+	case "/file/:name.:ext":
+		return 42
+	case "/file/:name":
+		return 43
+	case "/static/*filepath":
+		return 44
+	case "/static":
+		return 45
+	}
+	return -1
+}
+
+// routerKeys are the compiled keys.
+var routerKeys = [...]string{
+	"/",
+	"/path/to/route",
+	"/path/to/other",
+	"/path/to/route/a",
+	"/path/to/:param",
+	"/path/to/wildcard/*routepath",
+	"/path/to/:param1/:param2",
+	"/path/to/:param1/sep/:param2",
+	"/:year/:month/:day",
+	"/user/:id",
+	"/a/to/b/:param/*routepath",
+	"/:b",
+	"/*wildcard",
+	"/v:version/x",
+	"/range/:from-:to",
+	"/img_:id.png",
+	"/files/:name.:ext",
+	"/range/:from-:to<int>",
+	"/:lang-:region/help",
+	"/page/:n<int>px",
+	"/tag/:name",
+	"/tag/:name<int>-x",
+	"/t/:a-:b",
+	"/t/:a~:b",
+	"/files/*path",
+	"/files/:name",
+	"/files/:id<int>",
+	"/files/new",
+	"/files/:name/raw",
+	"/:section/list",
+	"/docs/:page",
+	"/admin/*path",
+	"/admin/login",
+	"/:lang/help",
+	"/help/:topic",
+	"/archive/:year<int>/:month<int>",
+	"/archive/:year<int>",
+	"/archive",
+	"/users/:id",
+	"/users",
+	"/users/:id/edit",
+	"/users/edit",
+	"/file/:name.:ext",
+	"/file/:name",
+	"/static/*filepath",
+	"/static",
+}
+
+// routerParamNames are the names of path parameters of the keys.
+var routerParamNames = [...][]string{
+	nil,
+	nil,
+	nil,
+	nil,
+	{"param"},
+	{"routepath"},
+	{"param1", "param2"},
+	{"param1", "param2"},
+	{"year", "month", "day"},
+	{"id"},
+	{"param", "routepath"},
+	{"b"},
+	{"wildcard"},
+	{"version"},
+	{"from", "to"},
+	{"id"},
+	{"name", "ext"},
+	{"from", "to"},
+	{"lang", "region"},
+	{"n"},
+	{"name"},
+	{"name"},
+	{"a", "b"},
+	{"a", "b"},
+	{"path"},
+	{"name"},
+	{"id"},
+	nil,
+	{"name"},
+	{"section"},
+	{"page"},
+	{"path"},
+	nil,
+	{"lang"},
+	{"topic"},
+	{"year", "month"},
+	{"year"},
+	nil,
+	{"id"},
+	nil,
+	{"id"},
+	nil,
+	{"name", "ext"},
+	{"name"},
+	{"filepath"},
+	nil,
+}
+
+// routerLeaves are the indexes of the leaves of the keys.
+var routerLeaves = [...]int{0, 20, 19, 21, 23, 22, 25, 24, 44, 34, 1, 40, 45, 39, 27, 17, 13, 26, 41, 18, 33, 32, 30, 31, 15, 12, 11, 10, 14, 43, 7, 3, 2, 42, 16, 6, 5, 4, 37, 35, 38, 36, 9, 8, 29, 28}
+
+// routerConstraints are the constraints of path parameters.
+var routerConstraints = [...]string{
+	"<int>",
+}
+
+// routerNoDelimiter is the delimiter function of the nodes that have no static children.
+func routerNoDelimiter(c byte) bool {
+	return false
+}
+
+func (r *Router) state0(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state1(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state1(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(0, params, m)
+	}
+	switch path[0] {
+	case 'a':
+		if r.state2(path[1:], params, m) {
+			return true
+		}
+	case 'd':
+		if len(path) >= 5 && path[:5] == "docs/" && r.state15(path[5:], params, m) {
+			return true
+		}
+	case 'f':
+		if len(path) >= 4 && path[:4] == "file" && r.state17(path[4:], params, m) {
+			return true
+		}
+	case 'h':
+		if len(path) >= 5 && path[:5] == "help/" && r.state30(path[5:], params, m) {
+			return true
+		}
+	case 'i':
+		if len(path) >= 4 && path[:4] == "img_" && r.state32(path[4:], params, m) {
+			return true
+		}
+	case 'p':
+		if len(path) >= 2 && path[:2] == "pa" && r.state35(path[2:], params, m) {
+			return true
+		}
+	case 'r':
+		if len(path) >= 6 && path[:6] == "range/" && r.state50(path[6:], params, m) {
+			return true
+		}
+	case 's':
+		if len(path) >= 6 && path[:6] == "static" && r.state55(path[6:], params, m) {
+			return true
+		}
+	case 't':
+		if r.state58(path[1:], params, m) {
+			return true
+		}
+	case 'u':
+		if len(path) >= 4 && path[:4] == "user" && r.state69(path[4:], params, m) {
+			return true
+		}
+	case 'v':
+		if r.state77(path[1:], params, m) {
+			return true
+		}
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter0) {
+		if r.state80(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return r.match(45, append(params, urlrouter.Param{Value: path}), m)
+}
+
+func (r *Router) state2(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if len(path) >= 6 && path[:6] == "/to/b/" && r.state3(path[6:], params, m) {
+			return true
+		}
+	case 'd':
+		if len(path) >= 5 && path[:5] == "dmin/" && r.state7(path[5:], params, m) {
+			return true
+		}
+	case 'r':
+		if len(path) >= 6 && path[:6] == "rchive" && r.state10(path[6:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state3(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter1) {
+		if r.state4(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state4(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state5(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state5(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	return r.match(1, append(params, urlrouter.Param{Value: path}), m)
+}
+
+func (r *Router) state7(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'l':
+		if len(path) >= 5 && path[:5] == "login" && r.state8(path[5:], params, m) {
+			return true
+		}
+	}
+	return r.match(3, append(params, urlrouter.Param{Value: path}), m)
+}
+
+func (r *Router) state8(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(2, params, m)
+}
+
+func (r *Router) state10(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(4, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if r.state11(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state11(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter1) {
+		if c := r.constraints[0]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state12(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state12(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(5, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if r.state13(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state13(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if c := r.constraints[0]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state14(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state14(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(6, params, m)
+}
+
+func (r *Router) state15(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state16(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state16(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(7, params, m)
+}
+
+func (r *Router) state17(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state18(path[1:], params, m) {
+			return true
+		}
+	case 's':
+		if len(path) >= 2 && path[:2] == "s/" && r.state22(path[2:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state18(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter2) {
+		if r.state19(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state19(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(8, params, m)
+	}
+	switch path[0] {
+	case '.':
+		if r.state20(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state20(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state21(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state21(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(9, params, m)
+}
+
+func (r *Router) state22(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'n':
+		if len(path) >= 3 && path[:3] == "new" && r.state23(path[3:], params, m) {
+			return true
+		}
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if c := r.constraints[0]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state24(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter3) {
+		if r.state25(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return r.match(15, append(params, urlrouter.Param{Value: path}), m)
+}
+
+func (r *Router) state23(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(10, params, m)
+}
+
+func (r *Router) state24(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(11, params, m)
+}
+
+func (r *Router) state25(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(12, params, m)
+	}
+	switch path[0] {
+	case '.':
+		if r.state26(path[1:], params, m) {
+			return true
+		}
+	case '/':
+		if len(path) >= 4 && path[:4] == "/raw" && r.state28(path[4:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state26(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state27(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state27(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(13, params, m)
+}
+
+func (r *Router) state28(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(14, params, m)
+}
+
+func (r *Router) state30(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state31(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state31(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(16, params, m)
+}
+
+func (r *Router) state32(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter2) {
+		if r.state33(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state33(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '.':
+		if len(path) >= 4 && path[:4] == ".png" && r.state34(path[4:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state34(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(17, params, m)
+}
+
+func (r *Router) state35(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'g':
+		if len(path) >= 3 && path[:3] == "ge/" && r.state36(path[3:], params, m) {
+			return true
+		}
+	case 't':
+		if len(path) >= 6 && path[:6] == "th/to/" && r.state39(path[6:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state36(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter4) {
+		if c := r.constraints[0]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state37(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state37(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'p':
+		if len(path) >= 2 && path[:2] == "px" && r.state38(path[2:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state38(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(18, params, m)
+}
+
+func (r *Router) state39(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'o':
+		if len(path) >= 5 && path[:5] == "other" && r.state40(path[5:], params, m) {
+			return true
+		}
+	case 'r':
+		if len(path) >= 5 && path[:5] == "route" && r.state41(path[5:], params, m) {
+			return true
+		}
+	case 'w':
+		if len(path) >= 9 && path[:9] == "wildcard/" && r.state43(path[9:], params, m) {
+			return true
+		}
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter1) {
+		if r.state45(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state40(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(19, params, m)
+}
+
+func (r *Router) state41(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(20, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if len(path) >= 2 && path[:2] == "/a" && r.state42(path[2:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state42(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(21, params, m)
+}
+
+func (r *Router) state43(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	return r.match(22, append(params, urlrouter.Param{Value: path}), m)
+}
+
+func (r *Router) state45(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(23, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if r.state46(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state46(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 's':
+		if len(path) >= 4 && path[:4] == "sep/" && r.state47(path[4:], params, m) {
+			return true
+		}
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state49(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state47(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state48(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state48(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(24, params, m)
+}
+
+func (r *Router) state49(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(25, params, m)
+}
+
+func (r *Router) state50(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter5) {
+		if r.state51(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state51(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '-':
+		if r.state52(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state52(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if c := r.constraints[0]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state53(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state54(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state53(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(26, params, m)
+}
+
+func (r *Router) state54(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(27, params, m)
+}
+
+func (r *Router) state55(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(28, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if r.state56(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state56(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	return r.match(29, append(params, urlrouter.Param{Value: path}), m)
+}
+
+func (r *Router) state58(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state59(path[1:], params, m) {
+			return true
+		}
+	case 'a':
+		if len(path) >= 3 && path[:3] == "ag/" && r.state65(path[3:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state59(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter6) {
+		if r.state60(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state60(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '-':
+		if r.state61(path[1:], params, m) {
+			return true
+		}
+	case '~':
+		if r.state63(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state61(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state62(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state62(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(30, params, m)
+}
+
+func (r *Router) state63(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state64(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state64(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(31, params, m)
+}
+
+func (r *Router) state65(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter5) {
+		if c := r.constraints[0]; c == nil || !c.Match(path[:end]) {
+			continue
+		}
+		if r.state66(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state68(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state66(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '-':
+		if len(path) >= 2 && path[:2] == "-x" && r.state67(path[2:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state67(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(32, params, m)
+}
+
+func (r *Router) state68(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(33, params, m)
+}
+
+func (r *Router) state69(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state70(path[1:], params, m) {
+			return true
+		}
+	case 's':
+		if r.state72(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state70(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state71(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state71(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(34, params, m)
+}
+
+func (r *Router) state72(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(35, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if r.state73(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state73(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'e':
+		if len(path) >= 4 && path[:4] == "edit" && r.state74(path[4:], params, m) {
+			return true
+		}
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter1) {
+		if r.state75(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state74(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(36, params, m)
+}
+
+func (r *Router) state75(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(37, params, m)
+	}
+	switch path[0] {
+	case '/':
+		if len(path) >= 5 && path[:5] == "/edit" && r.state76(path[5:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state76(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(38, params, m)
+}
+
+func (r *Router) state77(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter1) {
+		if r.state78(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state78(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if len(path) >= 2 && path[:2] == "/x" && r.state79(path[2:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state79(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(39, params, m)
+}
+
+func (r *Router) state80(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return r.match(40, params, m)
+	}
+	switch path[0] {
+	case '-':
+		if r.state81(path[1:], params, m) {
+			return true
+		}
+	case '/':
+		if r.state84(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state81(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter1) {
+		if r.state82(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state82(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if len(path) >= 5 && path[:5] == "/help" && r.state83(path[5:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state83(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(41, params, m)
+}
+
+func (r *Router) state84(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case 'h':
+		if len(path) >= 4 && path[:4] == "help" && r.state85(path[4:], params, m) {
+			return true
+		}
+	case 'l':
+		if len(path) >= 4 && path[:4] == "list" && r.state86(path[4:], params, m) {
+			return true
+		}
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerDelimiter1) {
+		if r.state87(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state85(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(42, params, m)
+}
+
+func (r *Router) state86(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(43, params, m)
+}
+
+func (r *Router) state87(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	switch path[0] {
+	case '/':
+		if r.state88(path[1:], params, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state88(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	if path == "" {
+		return false
+	}
+	var buf [8]int
+	for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, routerNoDelimiter) {
+		if r.state89(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) state89(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return path == "" && r.match(44, params, m)
+}
+
+func routerDelimiter0(c byte) bool {
+	switch c {
+	case '-', '/':
+		return true
+	}
+	return false
+}
+
+func routerDelimiter1(c byte) bool {
+	switch c {
+	case '/':
+		return true
+	}
+	return false
+}
+
+func routerDelimiter2(c byte) bool {
+	switch c {
+	case '.':
+		return true
+	}
+	return false
+}
+
+func routerDelimiter3(c byte) bool {
+	switch c {
+	case '.', '/':
+		return true
+	}
+	return false
+}
+
+func routerDelimiter4(c byte) bool {
+	switch c {
+	case 'p':
+		return true
+	}
+	return false
+}
+
+func routerDelimiter5(c byte) bool {
+	switch c {
+	case '-':
+		return true
+	}
+	return false
+}
+
+func routerDelimiter6(c byte) bool {
+	switch c {
+	case '-', '~':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/testutil"
+)
+
+// router is the urlrouter.Router of Router.
+type router struct{}
+
+func (router) New() urlrouter.URLRouter {
+	return NewRouter()
+}
+
+// constraintsRouter is the urlrouter.Router of ConstraintsRouter.
+type constraintsRouter struct{}
+
+func (constraintsRouter) New() urlrouter.URLRouter {
+	return NewConstraintsRouter()
+}
+
+func Test_Generated_Lookup(t *testing.T) {
+	testutil.Test_URLRouter_Lookup(t, router{})
+}
+
+func Test_Generated_Lookup_with_constraints(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_constraints(t, constraintsRouter{})
+}
+
+func Test_Generated_Lookup_with_params_in_segment(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_params_in_segment(t, router{})
+}
+
+func Test_Generated_Lookup_with_precedence(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_precedence(t, router{})
+}
+
+func Test_Generated_Lookup_with_optional_segments(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_optional_segments(t, router{})
+}
+
+func Test_Generated_Walk(t *testing.T) {
+	testutil.Test_URLRouter_Walk(t, constraintsRouter{})
+}
+
+func Test_Generated_LookupInto(t *testing.T) {
+	testutil.Test_URLRouter_LookupInto(t, router{})
+}
+
+func Test_Generated_LookupInto_allocs(t *testing.T) {
+	testutil.Test_URLRouter_LookupInto_allocs(t, router{}, "/path/to/route", "/path/to/hoge", "/2014/01/06", "/a/to/b/p1/some/wildcard/params")
+}
+
+func Test_Generated_Build(t *testing.T) {
+	testutil.Test_URLRouter_Build(t, router{})
+}
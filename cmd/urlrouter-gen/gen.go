@@ -0,0 +1,583 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+// key represents a key of the routing table file.
+type key struct {
+	Key string
+
+	// Position of the key in the file such as "routes.txt:3".
+	Pos string
+}
+
+// config represents the configuration of the generated file.
+type config struct {
+	// Name of the routing table file.
+	Source string
+
+	// Package name of the generated file.
+	Package string
+
+	// Type name of the generated URLRouter.
+	Type string
+
+	// Names of the constraint types that are registered by urlrouter.RegisterConstraintType at runtime.
+	Types []string
+}
+
+// nodeKind represents the kind of the edge from the parent node.
+type nodeKind int
+
+const (
+	staticNode nodeKind = iota
+	paramNode
+	wildcardNode
+)
+
+// node represents a node of the Radix Tree that the generated code is made from.
+// The nodes are explored in the same order as the radix package, so the generated URLRouter follows the precedence
+// rules of URLRouter.
+type node struct {
+	kind nodeKind
+
+	// Label of the edge from the parent node. It is empty if the node is a child of path parameter.
+	prefix string
+
+	// Static child nodes that are ordered by the first characters of the labels.
+	children []*node
+
+	// Child nodes of path parameter.
+	// The nodes that have a constraint are ordered by the constraint, and the node that has no constraint is the last.
+	params []*node
+
+	// Child node of wildcard path parameter.
+	wildcard *node
+
+	// Constraint of the path parameter that leads to this node.
+	constraint string
+
+	// Indexes of the keys that end at this node.
+	routes []int
+
+	// ID of the node, that is used in the name of the function of the node.
+	id int
+
+	// Index of the leaf of the routes, or -1 if the node has no routes.
+	leaf int
+}
+
+// add adds the key of route to the tree of nd.
+func (nd *node) add(key string, route int) {
+	for i := 0; i < len(key); {
+		switch key[i] {
+		case urlrouter.ParamCharacter:
+			_, constraint, next, _ := urlrouter.ParseParam(key, i)
+			nd, i = nd.paramChild(constraint), next
+		case urlrouter.WildcardCharacter:
+			_, _, next, _ := urlrouter.ParseParam(key, i)
+			if nd.wildcard == nil {
+				nd.wildcard = &node{kind: wildcardNode}
+			}
+			nd, i = nd.wildcard, next
+		default:
+			end := i + 1
+			for end < len(key) && !urlrouter.IsMetaChar(key[end]) {
+				end++
+			}
+			nd = nd.staticChild(key[i:end])
+			i += len(nd.prefix)
+		}
+	}
+	nd.routes = append(nd.routes, route)
+}
+
+// staticChild returns the static child node whose label is the longest common prefix of s and the label.
+// The child node will be created or split if needed.
+func (nd *node) staticChild(s string) *node {
+	i := sort.Search(len(nd.children), func(i int) bool { return nd.children[i].prefix[0] >= s[0] })
+	if i == len(nd.children) || nd.children[i].prefix[0] != s[0] {
+		child := &node{prefix: s}
+		nd.children = append(nd.children, nil)
+		copy(nd.children[i+1:], nd.children[i:])
+		nd.children[i] = child
+		return child
+	}
+	child := nd.children[i]
+	n := 0
+	for n < len(child.prefix) && n < len(s) && child.prefix[n] == s[n] {
+		n++
+	}
+	if n < len(child.prefix) {
+		rest := *child
+		rest.prefix = child.prefix[n:]
+		*child = node{prefix: child.prefix[:n], children: []*node{&rest}}
+	}
+	return child
+}
+
+// paramChild returns the child node of path parameter that has constraint.
+// A new node will be created if it doesn't exist.
+func (nd *node) paramChild(constraint string) *node {
+	for _, child := range nd.params {
+		if child.constraint == constraint {
+			return child
+		}
+	}
+	child := &node{kind: paramNode, constraint: constraint}
+	i := sort.Search(len(nd.params), func(i int) bool {
+		c := nd.params[i].constraint
+		return c == "" || constraint != "" && c > constraint
+	})
+	nd.params = append(nd.params, nil)
+	copy(nd.params[i+1:], nd.params[i:])
+	nd.params[i] = child
+	return child
+}
+
+// generator generates the source of the URLRouter.
+type generator struct {
+	config
+
+	// Prefix of the unexported identifiers of the package level.
+	Prefix string
+
+	Keys        []string
+	ParamNames  [][]string
+	Constraints []string
+
+	// Index of the leaf of each key.
+	Leaves []int
+
+	// Number of the leaves.
+	NumLeaves int
+
+	nodes       []*node
+	constraints map[string]int
+
+	// Sets of the characters of the delimiter functions. (see delimiterName)
+	delimiters []string
+}
+
+// generate returns the formatted source of the URLRouter that keys are compiled into.
+func generate(keys []key, cfg config) ([]byte, error) {
+	if !token.IsIdentifier(cfg.Package) {
+		return nil, fmt.Errorf("invalid package name %q", cfg.Package)
+	}
+	if r, _ := utf8.DecodeRuneInString(cfg.Type); !token.IsIdentifier(cfg.Type) || !unicode.IsUpper(r) {
+		return nil, fmt.Errorf("invalid type name %q: must be an exported identifier", cfg.Type)
+	}
+	g := &generator{config: cfg, constraints: make(map[string]int)}
+	r, size := utf8.DecodeRuneInString(cfg.Type)
+	g.Prefix = string(unicode.ToLower(r)) + cfg.Type[size:]
+	// positions are the positions of g.Keys.
+	var positions []string
+	for _, k := range keys {
+		expanded, names, err := expand(k.Key, cfg.Types)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", k.Pos, err)
+		}
+		for i, key := range expanded {
+			g.Keys = append(g.Keys, key)
+			g.ParamNames = append(g.ParamNames, names[i])
+			positions = append(positions, k.Pos)
+		}
+	}
+	if err := validate(g.Keys, positions); err != nil {
+		return nil, err
+	}
+	root := &node{}
+	for i, key := range g.Keys {
+		root.add(key, i)
+	}
+	g.Leaves = make([]int, len(g.Keys))
+	g.number(root)
+	var buf bytes.Buffer
+	if err := header.Execute(&buf, g); err != nil {
+		return nil, err
+	}
+	for _, nd := range g.nodes {
+		g.state(&buf, nd)
+	}
+	g.writeDelimiters(&buf)
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("BUG: generated source can't be formatted: %v", err)
+	}
+	return src, nil
+}
+
+// expand returns the keys that key is expanded into by urlrouter.ExpandOptional, and the names of path parameters
+// of each of them.
+// The constraints are checked by urlrouter.NewConstraint as well as urlrouter.LoadRoutes, except the constraint
+// types in types that will be registered at runtime.
+func expand(key string, types []string) (keys []string, names [][]string, err error) {
+	keys, err = urlrouter.ExpandOptional(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	names = make([][]string, len(keys))
+	for k, key := range keys {
+		for i := 0; i < len(key); i++ {
+			if !urlrouter.IsMetaChar(key[i]) {
+				continue
+			}
+			name, constraint, next, err := urlrouter.ParseParam(key, i)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, n := range names[k] {
+				if n == name {
+					return nil, nil, &urlrouter.DuplicateParamError{Key: key, Index: -1, Name: name, Offset: i}
+				}
+			}
+			if constraint != "" && !isRuntimeType(constraint, types) {
+				if _, err := urlrouter.NewConstraint(constraint); err != nil {
+					return nil, nil, err
+				}
+			}
+			names[k] = append(names[k], name)
+			i = next - 1
+		}
+	}
+	return keys, names, nil
+}
+
+// isRuntimeType returns whether constraint is a constraint type such as "<custom>" that is in types.
+func isRuntimeType(constraint string, types []string) bool {
+	if constraint[0] != '<' {
+		return false
+	}
+	for _, t := range types {
+		if constraint == "<"+t+">" {
+			return true
+		}
+	}
+	return false
+}
+
+// validate returns the conflicts of keys reported by urlrouter.Validate with the positions of keys.
+// The keys that differ only in the names of path parameters are reported as well as the duplicate keys, because
+// they are compiled into the same leaf. The other conflicts are allowed, e.g. "/user/:id" and "/user/:name/edit".
+func validate(keys, positions []string) error {
+	records := make([]urlrouter.Record, len(keys))
+	for i, key := range keys {
+		records[i] = urlrouter.NewRecord(unnamedKey(key), nil)
+	}
+	conflicts, _ := urlrouter.Validate(records).(urlrouter.Conflicts)
+	var errs urlrouter.Errors
+	for _, c := range conflicts {
+		if c.Kind != urlrouter.DuplicateKey {
+			continue
+		}
+		key, other := keys[c.Index], keys[c.OtherIndex]
+		if key == other {
+			errs = append(errs, fmt.Errorf("%s: duplicate key `%v` of %s", positions[c.Index], key, positions[c.OtherIndex]))
+		} else {
+			errs = append(errs, fmt.Errorf("%s: key `%v` differs from `%v` of %s only in the names of path parameters",
+				positions[c.Index], key, other, positions[c.OtherIndex]))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// unnamedKey returns key that the names of path parameters are replaced with their indexes, so the keys that differ
+// only in the names become the same.
+func unnamedKey(key string) string {
+	var buf bytes.Buffer
+	n := 0
+	for i := 0; i < len(key); i++ {
+		if !urlrouter.IsMetaChar(key[i]) {
+			buf.WriteByte(key[i])
+			continue
+		}
+		_, constraint, next, _ := urlrouter.ParseParam(key, i)
+		fmt.Fprintf(&buf, "%c%d%s", key[i], n, constraint)
+		n++
+		i = next - 1
+	}
+	return buf.String()
+}
+
+// number assigns the IDs to nd and its descendants in the order of the exploration, and the indexes of the leaves
+// in the order of the walk.
+func (g *generator) number(nd *node) {
+	nd.id, nd.leaf = len(g.nodes), -1
+	g.nodes = append(g.nodes, nd)
+	if len(nd.routes) > 0 {
+		nd.leaf = g.NumLeaves
+		g.NumLeaves++
+		for _, route := range nd.routes {
+			g.Leaves[route] = nd.leaf
+		}
+	}
+	if nd.constraint != "" {
+		if _, exists := g.constraints[nd.constraint]; !exists {
+			g.constraints[nd.constraint] = len(g.Constraints)
+			g.Constraints = append(g.Constraints, nd.constraint)
+		}
+	}
+	for _, child := range nd.children {
+		g.number(child)
+	}
+	for _, child := range nd.params {
+		g.number(child)
+	}
+	if nd.wildcard != nil {
+		g.number(nd.wildcard)
+	}
+}
+
+// state writes the function that looks up the rest of the path after the edge to nd.
+// It tries the static children, the children of path parameter and the child of wildcard path parameter in order,
+// and the key that ends with the wildcard path parameter is the last resort.
+// The function of a child of wildcard path parameter is called with non-empty path, the parent tries its leaf.
+func (g *generator) state(w *bytes.Buffer, nd *node) {
+	if nd.kind == wildcardNode && len(nd.children) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nfunc (r *%s) state%d(path string, params []urlrouter.Param, m *match.Matcher[int]) bool {\n", g.Type, nd.id)
+	if nd.kind != wildcardNode {
+		switch {
+		case len(nd.children) == 0 && len(nd.params) == 0 && nd.wildcard == nil:
+			fmt.Fprintf(w, "return path == \"\" && r.match(%d, params, m)\n}\n", nd.leaf)
+			return
+		case nd.leaf >= 0:
+			fmt.Fprintf(w, "if path == \"\" {\nreturn r.match(%d, params, m)\n}\n", nd.leaf)
+		default:
+			fmt.Fprintf(w, "if path == \"\" {\nreturn false\n}\n")
+		}
+	}
+	if len(nd.children) > 0 {
+		fmt.Fprintf(w, "switch path[0] {\n")
+		for _, child := range nd.children {
+			fmt.Fprintf(w, "case %s:\n", quoteByte(child.prefix[0]))
+			if n := len(child.prefix); n == 1 {
+				fmt.Fprintf(w, "if r.state%d(path[1:], params, m) {\nreturn true\n}\n", child.id)
+			} else {
+				fmt.Fprintf(w, "if len(path) >= %d && path[:%d] == %s && r.state%d(path[%d:], params, m) {\nreturn true\n}\n",
+					n, n, strconv.Quote(child.prefix), child.id, n)
+			}
+		}
+		fmt.Fprintf(w, "}\n")
+	}
+	if len(nd.params) > 0 || nd.wildcard != nil && len(nd.wildcard.children) > 0 {
+		fmt.Fprintf(w, "var buf [8]int\n")
+	}
+	for _, child := range nd.params {
+		fmt.Fprintf(w, "for _, end := range urlrouter.ParamEnds(buf[:0], path, 0, false, %s) {\n", g.delimiterName(child))
+		if child.constraint != "" {
+			fmt.Fprintf(w, "if c := r.constraints[%d]; c == nil || !c.Match(path[:end]) {\ncontinue\n}\n", g.constraints[child.constraint])
+		}
+		fmt.Fprintf(w, "if r.state%d(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {\nreturn true\n}\n}\n", child.id)
+	}
+	if child := nd.wildcard; child != nil {
+		if len(child.children) > 0 {
+			fmt.Fprintf(w, "for _, end := range urlrouter.WildcardEnds(buf[:0], path, 0, false, false, %s) {\n", g.delimiterName(child))
+			fmt.Fprintf(w, "if r.state%d(path[end:], append(params, urlrouter.Param{Value: path[:end]}), m) {\nreturn true\n}\n}\n", child.id)
+		}
+		if child.leaf >= 0 {
+			fmt.Fprintf(w, "return r.match(%d, append(params, urlrouter.Param{Value: path}), m)\n}\n", child.leaf)
+			return
+		}
+	}
+	fmt.Fprintf(w, "return false\n}\n")
+}
+
+// delimiterName returns the name of the function that reports whether a character is the first character of the
+// labels of the static children of nd. The functions are shared by the nodes that have the same characters, and
+// written by delimiters.
+func (g *generator) delimiterName(nd *node) string {
+	if len(nd.children) == 0 {
+		return g.Prefix + "NoDelimiter"
+	}
+	cs := make([]string, len(nd.children))
+	for i, child := range nd.children {
+		cs[i] = quoteByte(child.prefix[0])
+	}
+	set := strings.Join(cs, ", ")
+	for i, d := range g.delimiters {
+		if d == set {
+			return fmt.Sprintf("%sDelimiter%d", g.Prefix, i)
+		}
+	}
+	g.delimiters = append(g.delimiters, set)
+	return fmt.Sprintf("%sDelimiter%d", g.Prefix, len(g.delimiters)-1)
+}
+
+// writeDelimiters writes the functions of the names that are returned by delimiterName.
+func (g *generator) writeDelimiters(w *bytes.Buffer) {
+	for i, set := range g.delimiters {
+		fmt.Fprintf(w, "\nfunc %sDelimiter%d(c byte) bool {\nswitch c {\ncase %s:\nreturn true\n}\nreturn false\n}\n", g.Prefix, i, set)
+	}
+}
+
+// quoteByte returns a Go character literal of c.
+func quoteByte(c byte) string {
+	if c < utf8.RuneSelf {
+		return strconv.QuoteRune(rune(c))
+	}
+	return fmt.Sprintf(`'\x%02x'`, c)
+}
+
+var header = template.Must(template.New("header").Parse(`// Code generated by urlrouter-gen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+
+	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/match"
+)
+
+// {{.Type}} is a URLRouter that the keys of {{.Source}} are compiled into.
+// Build binds the values of records to the compiled keys, and the keys that aren't built never match.
+type {{.Type}} struct {
+	// Index of the key plus 1 that is built for each leaf, or 0 if the leaf isn't built.
+	routes [{{.NumLeaves}}]int
+
+	values      [{{.NumLeaves}}]interface{}
+	priorities  [{{.NumLeaves}}]int
+	maxPriority int
+	constraints [{{len .Constraints}}]*urlrouter.Constraint
+}
+
+var _ urlrouter.URLRouter = (*{{.Type}})(nil)
+
+// New{{.Type}} returns a new {{.Type}}.
+func New{{.Type}}() *{{.Type}} {
+	return &{{.Type}}{}
+}
+
+// Lookup returns result data of lookup from the routing table by given path.
+func (r *{{.Type}}) Lookup(path string) (data interface{}, params []urlrouter.Param) {
+	return r.LookupInto(path, nil)
+}
+
+// LookupInto is the same as Lookup, but path parameters are appended to dst[:0].
+func (r *{{.Type}}) LookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	m := match.New[int](r.maxPriority, urlrouter.Options{})
+	r.state0(path, dst[:0], &m)
+	leaf, matched, found := m.Result()
+	if !found {
+		return nil, dst[:0]
+	}
+	names := {{.Prefix}}ParamNames[r.routes[leaf]-1]
+	params = append(dst[:0], matched...)
+	for i := range params {
+		params[i].Name = names[i]
+	}
+	return r.values[leaf], params
+}
+
+// Build binds the values of records to the compiled keys.
+// Optional segments of keys are expanded by urlrouter.ExpandRecords, and all of the expanded keys must have been
+// compiled. If the records have the same key, the last one is built.
+func (r *{{.Type}}) Build(records []urlrouter.Record) error {
+	if _, err := urlrouter.ExpandRecords(records); err != nil {
+		return err
+	}
+	var built {{.Type}}
+	var errs urlrouter.Errors
+	for i, record := range records {
+		keys, _ := urlrouter.ExpandOptional(record.Key)
+		for _, key := range keys {
+			k := {{.Prefix}}KeyIndex(key)
+			if k < 0 {
+				errs = append(errs, fmt.Errorf("record %d '%v': key isn't compiled into {{.Type}}", i, key))
+				continue
+			}
+			leaf := {{.Prefix}}Leaves[k]
+			built.routes[leaf], built.values[leaf], built.priorities[leaf] = k+1, record.Value, record.Priority
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	for i, spec := range {{.Prefix}}Constraints {
+		c, err := urlrouter.NewConstraint(spec)
+		if err != nil {
+			return err
+		}
+		built.constraints[i] = c
+	}
+	built.maxPriority = urlrouter.MaxPriority(records)
+	*r = built
+	return nil
+}
+
+// Walk implements the urlrouter.Walker.
+// The routes are walked in the order of the compiled tree.
+func (r *{{.Type}}) Walk(fn func(key string, value interface{}) error) error {
+	for leaf, route := range r.routes {
+		if route == 0 {
+			continue
+		}
+		if err := fn({{.Prefix}}Keys[route-1], r.values[leaf]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match passes the leaf to m if it's built. It reports whether the lookup should be stopped.
+func (r *{{.Type}}) match(leaf int, params []urlrouter.Param, m *match.Matcher[int]) bool {
+	return r.routes[leaf] != 0 && m.Match(leaf, r.priorities[leaf], params)
+}
+
+// {{.Prefix}}KeyIndex returns an index of key in {{.Prefix}}Keys, or -1 if key isn't compiled.
+func {{.Prefix}}KeyIndex(key string) int {
+	switch key {
+{{- range $i, $key := .Keys}}
+	case {{printf "%q" $key}}:
+		return {{$i}}
+{{- end}}
+	}
+	return -1
+}
+
+// {{.Prefix}}Keys are the compiled keys.
+var {{.Prefix}}Keys = [...]string{
+{{- range .Keys}}
+	{{printf "%q" .}},
+{{- end}}
+}
+
+// {{.Prefix}}ParamNames are the names of path parameters of the keys.
+var {{.Prefix}}ParamNames = [...][]string{
+{{- range .ParamNames}}
+	{{if .}}{ {{- range $i, $name := .}}{{if $i}}, {{end}}{{printf "%q" $name}}{{end -}} }{{else}}nil{{end}},
+{{- end}}
+}
+
+// {{.Prefix}}Leaves are the indexes of the leaves of the keys.
+var {{.Prefix}}Leaves = [...]int{ {{- range $i, $leaf := .Leaves}}{{if $i}}, {{end}}{{$leaf}}{{end -}} }
+
+// {{.Prefix}}Constraints are the constraints of path parameters.
+var {{.Prefix}}Constraints = [...]string{
+{{- range .Constraints}}
+	{{printf "%q" .}},
+{{- end}}
+}
+
+// {{.Prefix}}NoDelimiter is the delimiter function of the nodes that have no static children.
+func {{.Prefix}}NoDelimiter(c byte) bool {
+	return false
+}
+`))
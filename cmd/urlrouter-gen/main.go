@@ -0,0 +1,101 @@
+// Command urlrouter-gen generates a Go source file of a URLRouter that the keys of a routing table are compiled into.
+//
+// Usage:
+//
+//	urlrouter-gen [-o output] [-pkg package] [-type name] [-types list] routes.txt
+//
+// The routing table file has a key of Record per line such as "/user/:id<int>" or "/static/*filepath".
+// Empty lines and lines that start with '#' are ignored. The keys that have optional segments are expanded by
+// urlrouter.ExpandOptional.
+// The duplicate keys and the keys that differ only in the names of path parameters are errors. The constraints are
+// checked when generating, the constraint types that are registered by urlrouter.RegisterConstraintType at runtime
+// must be listed by -types such as "-types slug,date".
+//
+// The generated URLRouter looks up a path by the functions that compare the bytes of the path with the keys, it has
+// neither maps nor nodes of a tree. Build binds the values of records to the compiled keys, so the routing table can
+// be built by the records that have any of the keys, and the other keys never match.
+// The generated URLRouter uses the default syntax and no Options.
+//
+// It is intended to be used by go generate, e.g.
+//
+//	//go:generate urlrouter-gen -o router.go routes.txt
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	output := flag.String("o", "", "output file name (default stdout)")
+	pkg := flag.String("pkg", "", "package name of the generated file (default the name of the directory of output)")
+	typeName := flag.String("type", "Router", "type name of the generated URLRouter")
+	types := flag.String("types", "", "comma-separated names of the constraint types that are registered at runtime")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: urlrouter-gen [-o output] [-pkg package] [-type name] [-types list] routes.txt\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var typeNames []string
+	if *types != "" {
+		typeNames = strings.Split(*types, ",")
+	}
+	if err := run(flag.Arg(0), *output, *pkg, *typeName, typeNames); err != nil {
+		fmt.Fprintf(os.Stderr, "urlrouter-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(input, output, pkg, typeName string, types []string) error {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	keys, err := parseRoutes(filepath.Base(input), data)
+	if err != nil {
+		return err
+	}
+	if pkg == "" {
+		pkg = "main"
+		if output != "" {
+			abs, err := filepath.Abs(output)
+			if err != nil {
+				return err
+			}
+			pkg = filepath.Base(filepath.Dir(abs))
+		}
+	}
+	src, err := generate(keys, config{Source: filepath.Base(input), Package: pkg, Type: typeName, Types: types})
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(output, src, 0644)
+}
+
+// parseRoutes returns the keys of the routing table file. name is used in the error messages.
+func parseRoutes(name string, data []byte) ([]key, error) {
+	var keys []key
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		s := strings.TrimSpace(string(line))
+		if s == "" || s[0] == '#' {
+			continue
+		}
+		keys = append(keys, key{Key: s, Pos: fmt.Sprintf("%s:%d", name, i+1)})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no keys", name)
+	}
+	return keys, nil
+}
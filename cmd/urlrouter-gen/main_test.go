@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseRoutes(t *testing.T) {
+	data := []byte("# comment\n/\n\n  /user/:id  \n/static/*filepath\r\n")
+	keys, err := parseRoutes("routes.txt", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual interface{} = keys
+	var expected interface{} = []key{
+		{Key: "/", Pos: "routes.txt:2"},
+		{Key: "/user/:id", Pos: "routes.txt:4"},
+		{Key: "/static/*filepath", Pos: "routes.txt:5"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	if _, err := parseRoutes("routes.txt", []byte("# comment\n\n")); err == nil {
+		t.Errorf("no error returned by the file that has no keys")
+	}
+}
+
+func Test_generate(t *testing.T) {
+	for _, testcase := range []struct {
+		keys     []key
+		cfg      config
+		expected string
+	}{
+		{[]key{{"/user/:id/:id", "routes.txt:3"}}, config{"routes.txt", "main", "Router", nil}, "routes.txt:3: duplicate path parameter `id`"},
+		{[]key{{"/user/:-x", "routes.txt:1"}}, config{"routes.txt", "main", "Router", nil}, "routes.txt:1: "},
+		{[]key{{"/user/:id([0-9]+", "routes.txt:2"}}, config{"routes.txt", "main", "Router", nil}, "routes.txt:2: "},
+		{[]key{{"/archive(/:year", "routes.txt:5"}}, config{"routes.txt", "main", "Router", nil}, "routes.txt:5: "},
+		{[]key{{"/user/:id<custom>", "routes.txt:1"}}, config{"routes.txt", "main", "Router", nil}, "routes.txt:1: "},
+		{[]key{{"/user/:id<custom>", "routes.txt:1"}}, config{"routes.txt", "main", "Router", []string{"slug"}}, "routes.txt:1: "},
+		{[]key{{"/user/:id", "routes.txt:1"}, {"/user/:id", "routes.txt:2"}}, config{"routes.txt", "main", "Router", nil}, "routes.txt:2: duplicate key `/user/:id` of routes.txt:1"},
+		{[]key{{"/static/*path", "routes.txt:1"}, {"/static(/*path)", "routes.txt:4"}}, config{"routes.txt", "main", "Router", nil}, "routes.txt:4: duplicate key `/static/*path` of routes.txt:1"},
+		{[]key{{"/user/:id<int>/:name", "routes.txt:1"}, {"/user/:num<int>/:title", "routes.txt:3"}}, config{"routes.txt", "main", "Router", nil}, "routes.txt:3: key `/user/:num<int>/:title` differs from `/user/:id<int>/:name` of routes.txt:1 only in the names of path parameters"},
+		{[]key{{"/", "routes.txt:1"}}, config{"routes.txt", "main", "router", nil}, "invalid type name"},
+		{[]key{{"/", "routes.txt:1"}}, config{"routes.txt", "my-pkg", "Router", nil}, "invalid package name"},
+	} {
+		_, err := generate(testcase.keys, testcase.cfg)
+		if err == nil || !strings.HasPrefix(err.Error(), testcase.expected) {
+			t.Errorf("%v with %+v expects an error that starts with %q, but %v", testcase.keys, testcase.cfg, testcase.expected, err)
+		}
+	}
+
+	// the constraint types that are registered at runtime are compiled, and they are checked by Build.
+	if _, err := generate([]key{{"/user/:id<custom>", "routes.txt:1"}}, config{"routes.txt", "main", "Router", []string{"slug", "custom"}}); err != nil {
+		t.Errorf("Expect %v, but %v", nil, err)
+	}
+
+	// the keys that conflict with each other in the other ways are compiled.
+	if _, err := generate([]key{{"/user/:id", "routes.txt:1"}, {"/user/:name/edit", "routes.txt:2"}, {"/user/:id<int>", "routes.txt:3"}}, config{"routes.txt", "main", "Router", nil}); err != nil {
+		t.Errorf("Expect %v, but %v", nil, err)
+	}
+}
+
+func Test_generate_example(t *testing.T) {
+	dir := "example"
+	for _, testcase := range []struct {
+		input, output, typeName string
+	}{
+		{"routes.txt", "router.go", "Router"},
+		{"constraints.txt", "constraints_router.go", "ConstraintsRouter"},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, testcase.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, err := parseRoutes(testcase.input, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := generate(keys, config{Source: testcase.input, Package: "example", Type: testcase.typeName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := os.ReadFile(filepath.Join(dir, testcase.output))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("example/%v is out of date, run go generate in example", testcase.output)
+		}
+	}
+}
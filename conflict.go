@@ -0,0 +1,298 @@
+package urlrouter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ConflictKind represents a kind of conflict between records.
+type ConflictKind int
+
+const (
+	// DuplicateKey represents that the same key is registered more than once.
+	DuplicateKey ConflictKind = iota + 1
+
+	// ParamNameMismatch represents that path parameters at the same position have different names.
+	ParamNameMismatch
+
+	// ShadowedByWildcard represents that a static key is matched by a wildcard key that has a higher Priority.
+	// The record of the static key will never be returned by the lookup.
+	ShadowedByWildcard
+)
+
+var conflictKindNames = map[ConflictKind]string{
+	DuplicateKey:       "duplicate key",
+	ParamNameMismatch:  "parameter name mismatch",
+	ShadowedByWildcard: "shadowed by wildcard",
+}
+
+// String returns a name of the ConflictKind.
+func (k ConflictKind) String() string {
+	if name, exists := conflictKindNames[k]; exists {
+		return name
+	}
+	return fmt.Sprintf("ConflictKind(%d)", int(k))
+}
+
+// ConflictError represents a conflict of a record.
+type ConflictError struct {
+	// Kind of the conflict.
+	Kind ConflictKind
+
+	// Index of Record in the records.
+	Index int
+
+	// Record that has the conflict.
+	Record Record
+
+	// Byte offset of the conflict in the key of Record.
+	Offset int
+
+	// Name of the path parameter of Record that conflicts, or empty if the conflict isn't caused by a path parameter.
+	Name string
+
+	// Index of Other in the records, or -1 if the conflict isn't caused by other record.
+	OtherIndex int
+
+	// Other record that conflicts with Record.
+	Other Record
+}
+
+// Error implements the error.Error.
+func (e *ConflictError) Error() string {
+	if e.OtherIndex < 0 {
+		return fmt.Sprintf("%v: record %d '%v' at offset %d", e.Kind, e.Index, e.Record.Key, e.Offset)
+	}
+	return fmt.Sprintf("%v: record %d '%v' at offset %d conflicts with record %d '%v'", e.Kind, e.Index, e.Record.Key, e.Offset, e.OtherIndex, e.Other.Key)
+}
+
+// Conflicts represents the conflicts of records.
+type Conflicts []*ConflictError
+
+// Error implements the error.Error.
+func (c Conflicts) Error() string {
+	msgs := make([]string, len(c))
+	for i, e := range c {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the conflicts as errors. It makes errors.As to find a *ConflictError.
+func (c Conflicts) Unwrap() []error {
+	errs := make([]error, len(c))
+	for i, e := range c {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Validate analyzes records and returns Conflicts if records conflict with each other.
+// It reports the following conflicts.
+//
+//	DuplicateKey:       "/user/:id" and "/user/:id"
+//	ParamNameMismatch:  "/user/:id" and "/user/:name/edit", or "/static/*filepath" and "/static/*path/edit"
+//	ShadowedByWildcard: "/static/*filepath" that has a higher Priority than "/static/favicon.ico"
+//
+// Path parameters that have different constraints aren't conflicted.
+// The keys that have optional segments are validated after expanding by ExpandOptional, then Record and Other of
+// ConflictError are the expanded records, and Index and OtherIndex are the indexes of the original records.
+// The keys that can't be parsed are ignored, those errors will be reported by Build of URLRouter.
+// It is the same as DefaultSyntax.Validate.
+func Validate(records []Record) error {
+	return DefaultSyntax.Validate(records)
+}
+
+// Validate analyzes records that written in s, and returns Conflicts if records conflict with each other.
+// See Validate.
+func (s *Syntax) Validate(records []Record) error {
+	var indexes []int
+	var expanded []Record
+	for i, record := range records {
+		keys, err := s.ExpandOptional(record.Key)
+		if err != nil {
+			keys = []string{record.Key}
+		}
+		for _, key := range keys {
+			record.Key = key
+			indexes = append(indexes, i)
+			expanded = append(expanded, record)
+		}
+	}
+	conflict := func(kind ConflictKind, i, offset, j int, name string) *ConflictError {
+		if j < 0 {
+			return &ConflictError{Kind: kind, Index: indexes[i], Record: expanded[i], Offset: offset, Name: name, OtherIndex: -1}
+		}
+		return &ConflictError{Kind: kind, Index: indexes[i], Record: expanded[i], Offset: offset, Name: name, OtherIndex: indexes[j], Other: expanded[j]}
+	}
+	records = expanded
+	var conflicts Conflicts
+	keys := make(map[string]int)
+	params := make(map[string]int)
+	wildcardPos := make(map[string]int)
+	var wildcards []int
+	for i, record := range records {
+		hasWildcard := false
+		if j, exists := keys[record.Key]; exists {
+			conflicts = append(conflicts, conflict(DuplicateKey, i, 0, j, ""))
+			continue
+		}
+		keys[record.Key] = i
+		var prefix bytes.Buffer
+		for k := 0; k < len(record.Key); k++ {
+			c := record.Key[k]
+			if !s.IsMetaChar(c) {
+				prefix.WriteByte(c)
+				continue
+			}
+			name, constraint, next, err := s.ParseParam(record.Key, k)
+			if err != nil {
+				break
+			}
+			positions := params
+			if c == s.wildcardChar {
+				positions, hasWildcard = wildcardPos, true
+			}
+			pos := prefix.String() + string(c) + constraint
+			if j, exists := positions[pos]; exists {
+				if s.paramNameAt(records[j].Key, prefix.String(), constraint) != name {
+					conflicts = append(conflicts, conflict(ParamNameMismatch, i, k, j, name))
+				}
+			} else {
+				positions[pos] = i
+			}
+			prefix.WriteString(pos[prefix.Len():])
+			k = next - 1
+		}
+		if hasWildcard {
+			wildcards = append(wildcards, i)
+		}
+	}
+	for _, j := range wildcards {
+		for i, record := range records {
+			if record.Priority >= records[j].Priority {
+				continue
+			}
+			if s.HasMetaChar(record.Key) {
+				continue
+			}
+			if offset, matched := s.matchWildcard(records[j].Key, record.Key); matched {
+				conflicts = append(conflicts, conflict(ShadowedByWildcard, i, offset, j, ""))
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts
+	}
+	return nil
+}
+
+// paramNameAt returns a name of the path parameter that follows prefix in key.
+// prefix is a normalized key that doesn't contain names of path parameters.
+func (s *Syntax) paramNameAt(key, prefix, constraint string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(key); i++ {
+		if !s.IsMetaChar(key[i]) {
+			buf.WriteByte(key[i])
+			continue
+		}
+		name, c, next, err := s.ParseParam(key, i)
+		if err != nil {
+			return ""
+		}
+		if buf.String() == prefix && c == constraint {
+			return name
+		}
+		buf.WriteString(key[i:i+1] + c)
+		i = next - 1
+	}
+	return ""
+}
+
+// matchWildcard returns whether path matches key that has a wildcard path parameter,
+// and an offset of path where the first wildcard path parameter begins.
+func (s *Syntax) matchWildcard(key, path string) (offset int, matched bool) {
+	return s.matchKey(key, 0, path, 0, -1)
+}
+
+// matchKey returns whether path[j:] matches key[i:]. offset is an offset of path where the first wildcard path
+// parameter begins, or -1 if it hasn't appeared yet. It returns the offset if path matches key that has a wildcard
+// path parameter.
+func (s *Syntax) matchKey(key string, i int, path string, j, offset int) (int, bool) {
+	for ; i < len(key); i++ {
+		if !s.IsMetaChar(key[i]) {
+			if j >= len(path) || path[j] != key[i] {
+				return -1, false
+			}
+			j++
+			continue
+		}
+		_, constraint, next, err := s.ParseParam(key, i)
+		if err != nil {
+			return -1, false
+		}
+		if key[i] == s.wildcardChar {
+			if offset < 0 {
+				offset = j
+			}
+			if next == len(key) {
+				return offset, j < len(path)
+			}
+			for end := j + 1; end < len(path); end++ {
+				if path[end] == key[next] {
+					if offset, matched := s.matchKey(key, next, path, end, offset); matched {
+						return offset, true
+					}
+				}
+			}
+			return -1, false
+		}
+		end := s.paramValueEnd(path, j, s.paramDelimiter(key, next))
+		if end < 0 {
+			return -1, false
+		}
+		if constraint != "" {
+			c, err := NewConstraint(constraint)
+			if err != nil || !c.Match(path[j:end]) {
+				return -1, false
+			}
+		}
+		i, j = next-1, end
+	}
+	return offset, offset >= 0 && j == len(path)
+}
+
+// WithValidation returns a Router that validates records by Validate before building.
+// The records are validated with DefaultSyntax, use WithSyntaxValidation for the URLRouters that have other Syntax.
+func WithValidation(router Router) Router {
+	return WithSyntaxValidation(router, DefaultSyntax)
+}
+
+// WithSyntaxValidation returns a Router that validates records by Validate of syntax before building.
+func WithSyntaxValidation(router Router, syntax *Syntax) Router {
+	return &validatingRouter{router: router, syntax: syntax}
+}
+
+type validatingRouter struct {
+	router Router
+	syntax *Syntax
+}
+
+// New returns a new URLRouter that validates records before building.
+func (r *validatingRouter) New() URLRouter {
+	return &validatingURLRouter{URLRouter: r.router.New(), syntax: r.syntax}
+}
+
+type validatingURLRouter struct {
+	URLRouter
+	syntax *Syntax
+}
+
+// Build validates records by Validate, and builds URLRouter if records have no conflict.
+func (r *validatingURLRouter) Build(records []Record) error {
+	if err := r.syntax.Validate(records); err != nil {
+		return err
+	}
+	return r.URLRouter.Build(records)
+}
@@ -0,0 +1,107 @@
+package urlrouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Validate(t *testing.T) {
+	records := []Record{
+		NewRecord("/", "testroute0"),
+		NewRecord("/user/:id", "testroute1"),
+		NewRecord("/user/:id", "testroute2"),
+		NewRecord("/user/:name/edit", "testroute3"),
+		NewRecord("/user/:id<int>/posts", "testroute4"),
+		NewRecord("/user/:num<int>/likes", "testroute5"),
+		{Key: "/static/*filepath", Value: "testroute6", Priority: 1},
+		NewRecord("/static/favicon.ico", "testroute7"),
+		NewRecord("/static", "testroute8"),
+		NewRecord("/files/*path/edit", "testroute9"),
+		NewRecord("/:year/:month", "testroute10"),
+		NewRecord("/:year/:day/x", "testroute11"),
+		NewRecord("/post/:id", "testroute12"),
+		NewRecord("/post/:id/:name", "testroute13"),
+		{Key: "/a/:p/*w", Value: "testroute14", Priority: 1},
+		NewRecord("/a/b/c/d", "testroute15"),
+		NewRecord("/a/b", "testroute16"),
+		NewRecord("/docs/index.html", "testroute17"),
+		{Key: "/docs/*page", Value: "testroute18", Priority: 1},
+		NewRecord("/static/*path", "testroute19"),
+		NewRecord("/:y/:m/*rest", "testroute20"),
+		NewRecord("/:year/:month/*rest", "testroute21"),
+		NewRecord("/b/:x?/:y?", "testroute22"),
+		NewRecord("/c(/:id)", "testroute23"),
+		NewRecord("/c", "testroute24"),
+		NewRecord("/files/*name/view", "testroute25"),
+		{Key: "/repos/*path/blob", Value: "testroute26", Priority: 1},
+		NewRecord("/repos/a/b/blob", "testroute27"),
+		NewRecord("/repos/a/b/tree", "testroute28"),
+	}
+	err := Validate(records)
+	conflicts, ok := err.(Conflicts)
+	if !ok {
+		t.Fatalf("Expect Conflicts, but %#v", err)
+	}
+	var actual interface{} = conflicts
+	var expected interface{} = Conflicts{
+		{Kind: DuplicateKey, Index: 2, Record: records[2], Offset: 0, OtherIndex: 1, Other: records[1]},
+		{Kind: ParamNameMismatch, Index: 3, Record: records[3], Offset: 6, Name: "name", OtherIndex: 1, Other: records[1]},
+		{Kind: ParamNameMismatch, Index: 5, Record: records[5], Offset: 6, Name: "num", OtherIndex: 4, Other: records[4]},
+		{Kind: ParamNameMismatch, Index: 11, Record: records[11], Offset: 7, Name: "day", OtherIndex: 10, Other: records[10]},
+		{Kind: ParamNameMismatch, Index: 19, Record: records[19], Offset: 8, Name: "path", OtherIndex: 6, Other: records[6]},
+		{Kind: ParamNameMismatch, Index: 20, Record: records[20], Offset: 1, Name: "y", OtherIndex: 10, Other: records[10]},
+		{Kind: ParamNameMismatch, Index: 20, Record: records[20], Offset: 4, Name: "m", OtherIndex: 10, Other: records[10]},
+		{Kind: ParamNameMismatch, Index: 22, Record: NewRecord("/b/:y", "testroute22"), Offset: 3, Name: "y", OtherIndex: 22, Other: NewRecord("/b/:x/:y", "testroute22")},
+		{Kind: DuplicateKey, Index: 24, Record: records[24], Offset: 0, OtherIndex: 23, Other: NewRecord("/c", "testroute23")},
+		{Kind: ParamNameMismatch, Index: 25, Record: records[25], Offset: 7, Name: "name", OtherIndex: 9, Other: records[9]},
+		{Kind: ShadowedByWildcard, Index: 7, Record: records[7], Offset: 8, OtherIndex: 6, Other: records[6]},
+		{Kind: ShadowedByWildcard, Index: 15, Record: records[15], Offset: 5, OtherIndex: 14, Other: records[14]},
+		{Kind: ShadowedByWildcard, Index: 17, Record: records[17], Offset: 6, OtherIndex: 18, Other: records[18]},
+		{Kind: ShadowedByWildcard, Index: 27, Record: records[27], Offset: 7, OtherIndex: 26, Other: records[26]},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	if err := Validate([]Record{
+		NewRecord("/", "testroute0"),
+		NewRecord("/user/:id", "testroute1"),
+		NewRecord("/user/:id/edit", "testroute2"),
+		NewRecord("/user/:name<alpha>", "testroute3"),
+		NewRecord("/static/*filepath", "testroute4"),
+		NewRecord("/static/favicon.ico", "testroute5"),
+		{Key: "/static/robots.txt", Value: "testroute6", Priority: 1},
+		{Key: "/docs/*page", Value: "testroute7", Priority: 1},
+		{Key: "/docs/index.html", Value: "testroute8", Priority: 1},
+	}); err != nil {
+		t.Errorf("Expect nil, but %v", err)
+	}
+}
+
+func Test_ConflictError_Error(t *testing.T) {
+	for _, testcase := range []struct {
+		err      *ConflictError
+		expected string
+	}{
+		{&ConflictError{Kind: DuplicateKey, Index: 2, Record: Record{Key: "/a"}, OtherIndex: 1, Other: Record{Key: "/a"}}, "duplicate key: record 2 '/a' at offset 0 conflicts with record 1 '/a'"},
+		{&ConflictError{Kind: ConflictKind(99), Index: 0, Record: Record{Key: "/a"}, Offset: 1, OtherIndex: -1}, "ConflictKind(99): record 0 '/a' at offset 1"},
+	} {
+		if actual := testcase.err.Error(); actual != testcase.expected {
+			t.Errorf("Expect %q, but %q", testcase.expected, actual)
+		}
+	}
+}
+
+func Test_WithValidation(t *testing.T) {
+	router := WithValidation(&staticRouter{})
+	r := router.New()
+	if err := r.Build([]Record{NewRecord("/a", "testroute0"), NewRecord("/a", "testroute1")}); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+	if err := r.Build([]Record{NewRecord("/a", "testroute0"), NewRecord("/b", "testroute1")}); err != nil {
+		t.Fatal(err)
+	}
+	if actual, _ := r.Lookup("/b"); actual != "testroute1" {
+		t.Errorf("Expect %v, but %v", "testroute1", actual)
+	}
+}
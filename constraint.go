@@ -0,0 +1,75 @@
+package urlrouter
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+var (
+	constraintTypesMu sync.RWMutex
+	constraintTypes   = map[string]string{
+		"int":   `-?[0-9]+`,
+		"uint":  `[0-9]+`,
+		"alpha": `[A-Za-z]+`,
+		"alnum": `[A-Za-z0-9]+`,
+		"hex":   `[0-9A-Fa-f]+`,
+		"uuid":  `[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}`,
+	}
+)
+
+// Constraint represents a constraint of a value of path parameter.
+// A path parameter can have a constraint in the key like "/user/:id<int>" or "/file/:name([a-z]+)".
+// The former is a named constraint type (see RegisterConstraintType), and the latter is a regular expression
+// that must match the whole of the value.
+type Constraint struct {
+	spec   string
+	regexp *regexp.Regexp
+}
+
+// NewConstraint returns a new Constraint from spec such as "<int>" or "([0-9]+)".
+func NewConstraint(spec string) (*Constraint, error) {
+	var pattern string
+	switch {
+	case len(spec) > 2 && spec[0] == '<' && spec[len(spec)-1] == '>':
+		constraintTypesMu.RLock()
+		p, exists := constraintTypes[spec[1:len(spec)-1]]
+		constraintTypesMu.RUnlock()
+		if !exists {
+			return nil, fmt.Errorf("unknown constraint type `%v`", spec)
+		}
+		pattern = p
+	case len(spec) > 2 && spec[0] == '(' && spec[len(spec)-1] == ')':
+		pattern = spec[1 : len(spec)-1]
+	default:
+		return nil, fmt.Errorf("invalid constraint `%v`", spec)
+	}
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint `%v`: %v", spec, err)
+	}
+	return &Constraint{spec: spec, regexp: re}, nil
+}
+
+// Match returns whether value satisfies the constraint.
+func (c *Constraint) Match(value string) bool {
+	return c.regexp.MatchString(value)
+}
+
+// String returns the spec of the constraint.
+func (c *Constraint) String() string {
+	return c.spec
+}
+
+// RegisterConstraintType registers a named constraint type that can be used as `:name<typ>` in keys.
+// pattern is a regular expression that must match the whole of a value.
+// Builtin types are "int", "uint", "alpha", "alnum", "hex" and "uuid".
+func RegisterConstraintType(typ, pattern string) error {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return err
+	}
+	constraintTypesMu.Lock()
+	defer constraintTypesMu.Unlock()
+	constraintTypes[typ] = pattern
+	return nil
+}
@@ -0,0 +1,69 @@
+package urlrouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_NewConstraint(t *testing.T) {
+	for _, testcase := range []struct {
+		spec    string
+		matches []string
+		unmatch []string
+	}{
+		{"<int>", []string{"0", "123", "-1"}, []string{"", "a", "1a", "1.0"}},
+		{"<uint>", []string{"0", "123"}, []string{"-1", "a"}},
+		{"<alpha>", []string{"a", "Abc"}, []string{"", "a1"}},
+		{"<alnum>", []string{"a1", "Abc"}, []string{"", "a-1"}},
+		{"<hex>", []string{"0f", "FF"}, []string{"0g"}},
+		{"<uuid>", []string{"01234567-89ab-cdef-0123-456789ABCDEF"}, []string{"01234567-89ab-cdef-0123"}},
+		{"([a-z]+)", []string{"a", "abc"}, []string{"", "a1", "1a"}},
+		{"(a|bc)", []string{"a", "bc"}, []string{"ab", "abc"}},
+	} {
+		c, err := NewConstraint(testcase.spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", testcase.spec, err)
+			continue
+		}
+		if actual, expected := c.String(), testcase.spec; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expect %v, but %v", expected, actual)
+		}
+		for _, v := range testcase.matches {
+			if !c.Match(v) {
+				t.Errorf("%q expects to match %q, but not", testcase.spec, v)
+			}
+		}
+		for _, v := range testcase.unmatch {
+			if c.Match(v) {
+				t.Errorf("%q expects not to match %q, but matched", testcase.spec, v)
+			}
+		}
+	}
+
+	for _, spec := range []string{"", "<>", "<unknown>", "()", "([a-z)", "int"} {
+		if _, err := NewConstraint(spec); err == nil {
+			t.Errorf("%q expects error, but nil", spec)
+		}
+	}
+}
+
+func Test_RegisterConstraintType(t *testing.T) {
+	defer func() {
+		constraintTypesMu.Lock()
+		delete(constraintTypes, "lower")
+		constraintTypesMu.Unlock()
+	}()
+	if err := RegisterConstraintType("lower", `[a-z]+`); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConstraint("<lower>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Match("abc") || c.Match("ABC") {
+		t.Errorf("constraint <lower> doesn't work")
+	}
+	if err := RegisterConstraintType("invalid", `[a-z`); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+}
@@ -0,0 +1,405 @@
+package doublearray
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+const (
+	// Magic number of the binary format of DoubleArray.
+	binaryMagic = "KUDA"
+
+	// Version of the binary format of DoubleArray.
+	binaryVersion = 1
+)
+
+// maxTreeDepth is the maximum depth of the nested trees in the binary format.
+// The trees are nested by each path parameter of a key, and the limit prevents a broken binary from exhausting the
+// stack of the decoder.
+var maxTreeDepth = 1024
+
+// errTooDeepTrees is returned when the trees are nested deeper than maxTreeDepth.
+var errTooDeepTrees = errors.New("doublearray: too deep nested trees")
+
+const (
+	optionCaseInsensitive byte = 1 << iota
+	optionNormalize
+	optionShortestWildcard
+)
+
+const (
+	flagData byte = 1 << iota
+	flagParamTree
+	flagWildcardTree
+)
+
+// ValueCodec is an interface that encodes and decodes the values of records for the binary format.
+type ValueCodec interface {
+	// EncodeValue returns an encoded v.
+	EncodeValue(v interface{}) ([]byte, error)
+
+	// DecodeValue returns a value that decoded from data.
+	DecodeValue(data []byte) (interface{}, error)
+}
+
+// GobCodec is a ValueCodec that uses encoding/gob.
+// The concrete types of values must be registered by gob.Register.
+type GobCodec struct{}
+
+// EncodeValue implements the ValueCodec.EncodeValue.
+func (c GobCodec) EncodeValue(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeValue implements the ValueCodec.DecodeValue.
+func (c GobCodec) DecodeValue(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler.
+// Values of records are encoded by da.Codec, or GobCodec if it is nil.
+func (da *DoubleArray) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := da.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler.
+// Values of records are decoded by da.Codec, or GobCodec if it is nil.
+func (da *DoubleArray) UnmarshalBinary(data []byte) error {
+	_, err := da.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// WriteTo writes the binary format of the Double-Array routing table to w.
+// It implements the io.WriterTo.
+func (da *DoubleArray) WriteTo(w io.Writer) (n int64, err error) {
+	e := &encoder{w: bufio.NewWriter(w), codec: da.codec()}
+	e.writeString(binaryMagic)
+	e.writeUvarint(binaryVersion)
+	e.writeVarint(int64(da.maxPriority))
+	var options byte
+	if da.opts.CaseInsensitive {
+		options |= optionCaseInsensitive
+	}
+	if da.opts.Normalize {
+		options |= optionNormalize
+	}
+	if da.opts.ShortestWildcard {
+		options |= optionShortestWildcard
+	}
+	e.write([]byte{options})
+	syntax := da.opts.SyntaxOrDefault()
+	e.write([]byte{syntax.ParamChar(), syntax.WildcardChar()})
+	e.writeBytes([]byte(syntax.Separators()))
+	e.writeTree(da.static, 1)
+	e.writeTree(da.param, 1)
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.n, e.err
+}
+
+// ReadFrom reads the binary format of the Double-Array routing table from r, and replaces the routing table of da.
+// The options of da are also replaced by the options that the routing table was built with.
+// It implements the io.ReaderFrom.
+func (da *DoubleArray) ReadFrom(r io.Reader) (n int64, err error) {
+	d := &decoder{r: bufio.NewReader(r), codec: da.codec()}
+	if magic := d.readBytes(uint64(len(binaryMagic))); d.err == nil && string(magic) != binaryMagic {
+		return d.n, errors.New("doublearray: invalid binary format")
+	}
+	if version := d.readUvarint(); d.err == nil && version != binaryVersion {
+		return d.n, fmt.Errorf("doublearray: unsupported binary format version %d", version)
+	}
+	maxPriority := int(d.readVarint())
+	options := d.readByte()
+	paramChar, wildcardChar := d.readByte(), d.readByte()
+	separators := d.readBytes(d.readLength())
+	static, param := d.readTree(1), d.readTree(1)
+	if d.err != nil {
+		if d.err == io.EOF {
+			d.err = io.ErrUnexpectedEOF
+		}
+		return d.n, d.err
+	}
+	opts := urlrouter.Options{
+		CaseInsensitive:  options&optionCaseInsensitive != 0,
+		Normalize:        options&optionNormalize != 0,
+		ShortestWildcard: options&optionShortestWildcard != 0,
+	}
+	if def := urlrouter.DefaultSyntax; paramChar != def.ParamChar() || wildcardChar != def.WildcardChar() || string(separators) != def.Separators() {
+		syntax, err := urlrouter.NewSyntax(paramChar, wildcardChar, string(separators))
+		if err != nil {
+			return d.n, fmt.Errorf("doublearray: invalid syntax in binary format: %v", err)
+		}
+		opts.Syntax = syntax
+	}
+	da.static, da.param, da.maxPriority, da.opts = static, param, maxPriority, opts
+	return d.n, nil
+}
+
+func (da *DoubleArray) codec() ValueCodec {
+	if da.Codec == nil {
+		return GobCodec{}
+	}
+	return da.Codec
+}
+
+// encoder represents an encoder of the binary format.
+type encoder struct {
+	w     *bufio.Writer
+	codec ValueCodec
+	buf   [binary.MaxVarintLen64]byte
+	n     int64
+	err   error
+}
+
+func (e *encoder) write(p []byte) {
+	if e.err != nil {
+		return
+	}
+	n, err := e.w.Write(p)
+	e.n += int64(n)
+	e.err = err
+}
+
+func (e *encoder) writeUvarint(v uint64) {
+	e.write(e.buf[:binary.PutUvarint(e.buf[:], v)])
+}
+
+func (e *encoder) writeVarint(v int64) {
+	e.write(e.buf[:binary.PutVarint(e.buf[:], v)])
+}
+
+func (e *encoder) writeString(s string) {
+	e.write([]byte(s))
+}
+
+func (e *encoder) writeBytes(p []byte) {
+	e.writeUvarint(uint64(len(p)))
+	e.write(p)
+}
+
+// writeTree writes da at depth of the nested trees.
+func (e *encoder) writeTree(da *doubleArray, depth int) {
+	if depth > maxTreeDepth && e.err == nil {
+		e.err = errTooDeepTrees
+	}
+	e.writeUvarint(uint64(len(da.bc)))
+	for _, bc := range da.bc {
+		e.writeVarint(int64(bc.base))
+		e.writeVarint(int64(bc.check))
+		if bc.hasParams {
+			e.write([]byte{1})
+		} else {
+			e.write([]byte{0})
+		}
+	}
+	indexes := make([]int, 0, len(da.node))
+	for idx := range da.node {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	e.writeUvarint(uint64(len(indexes)))
+	for _, idx := range indexes {
+		nd := da.node[idx]
+		var flags byte
+		if nd.data != nil {
+			flags |= flagData
+		}
+		if len(nd.paramTrees) > 0 {
+			flags |= flagParamTree
+		}
+		if nd.wildcardTree != nil {
+			flags |= flagWildcardTree
+		}
+		e.writeUvarint(uint64(idx))
+		e.write([]byte{flags})
+		e.writeUvarint(uint64(len(nd.paramNames)))
+		for _, name := range nd.paramNames {
+			e.writeBytes([]byte(name))
+		}
+		if nd.data != nil && e.err == nil {
+			data, err := e.codec.EncodeValue(nd.data)
+			if err != nil {
+				e.err = err
+				return
+			}
+			e.writeBytes(data)
+			e.writeVarint(int64(nd.priority))
+		}
+		if len(nd.paramTrees) > 0 {
+			e.writeUvarint(uint64(len(nd.paramTrees)))
+			for _, tree := range nd.paramTrees {
+				var constraint string
+				if tree.constraint != nil {
+					constraint = tree.constraint.String()
+				}
+				e.writeBytes([]byte(constraint))
+				e.writeTree(tree, depth+1)
+			}
+		}
+		if nd.wildcardTree != nil {
+			e.writeTree(nd.wildcardTree, depth+1)
+		}
+	}
+}
+
+// decoder represents a decoder of the binary format.
+type decoder struct {
+	r     *bufio.Reader
+	codec ValueCodec
+	n     int64
+	err   error
+}
+
+func (d *decoder) ReadByte() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err == nil {
+		d.n++
+	}
+	return c, err
+}
+
+func (d *decoder) readByte() byte {
+	if d.err != nil {
+		return 0
+	}
+	var c byte
+	c, d.err = d.ReadByte()
+	return c
+}
+
+func (d *decoder) readUvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	var v uint64
+	v, d.err = binary.ReadUvarint(d)
+	return v
+}
+
+func (d *decoder) readVarint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	var v int64
+	v, d.err = binary.ReadVarint(d)
+	return v
+}
+
+func (d *decoder) readBytes(size uint64) []byte {
+	if d.err != nil {
+		return nil
+	}
+	// the buffer grows as the data is read, so a broken size doesn't allocate a huge buffer.
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, d.r, int64(size))
+	d.n += n
+	d.err = err
+	return buf.Bytes()
+}
+
+func (d *decoder) readLength() uint64 {
+	size := d.readUvarint()
+	if d.err == nil && size > 1<<30 {
+		d.err = errors.New("doublearray: too large length in binary format")
+	}
+	return size
+}
+
+// readTree reads a tree at depth of the nested trees.
+func (d *decoder) readTree(depth int) *doubleArray {
+	da := &doubleArray{node: make(map[int]*node)}
+	if depth > maxTreeDepth && d.err == nil {
+		d.err = errTooDeepTrees
+	}
+	n := int(d.readLength())
+	if d.err == nil && n == 0 {
+		// the root is always used.
+		d.err = errors.New("doublearray: broken BASE/CHECK in binary format")
+	}
+	// the children of BASE are within the array, so BASE must be less than the end of the array plus a byte.
+	size := n + 0x100
+	// the array grows as BASE/CHECK are read, so a broken length doesn't allocate a huge array.
+	for i := 0; i < n && d.err == nil; i++ {
+		bc := baseCheck{base: int(d.readVarint()), check: int(d.readVarint()), hasParams: d.readByte() != 0}
+		// the root has no parent, so the children never form a cycle.
+		if d.err == nil && (bc.base < 0 || bc.base >= size || bc.check < -1 || bc.check >= n || i == 0 && bc.check != -1) {
+			d.err = errors.New("doublearray: broken BASE/CHECK in binary format")
+		}
+		da.bc = append(da.bc, bc)
+	}
+	for i, n := 0, d.readLength(); uint64(i) < n && d.err == nil; i++ {
+		idx := int(d.readUvarint())
+		if d.err == nil && idx != 0 && idx >= len(da.bc) {
+			d.err = errors.New("doublearray: broken node index in binary format")
+		}
+		flags := d.readByte()
+		nd := &node{}
+		for j, n := uint64(0), d.readLength(); j < n && d.err == nil; j++ {
+			nd.paramNames = append(nd.paramNames, string(d.readBytes(d.readLength())))
+		}
+		if flags&flagData != 0 {
+			data := d.readBytes(d.readLength())
+			if d.err != nil {
+				return nil
+			}
+			if nd.data, d.err = d.codec.DecodeValue(data); d.err != nil {
+				return nil
+			}
+			nd.priority = int(d.readVarint())
+		}
+		if flags&flagParamTree != 0 {
+			n := d.readLength()
+			if d.err == nil && n == 0 {
+				d.err = errors.New("doublearray: broken node index in binary format")
+			}
+			for j := uint64(0); j < n && d.err == nil; j++ {
+				constraint := string(d.readBytes(d.readLength()))
+				tree := d.readTree(depth + 1)
+				if d.err != nil {
+					return nil
+				}
+				if constraint != "" {
+					if tree.constraint, d.err = urlrouter.NewConstraint(constraint); d.err != nil {
+						return nil
+					}
+				}
+				nd.insertParamTree(tree)
+			}
+		}
+		if flags&flagWildcardTree != 0 {
+			nd.wildcardTree = d.readTree(depth + 1)
+		}
+		da.node[idx] = nd
+	}
+	// the lookup expects that the slots that have path parameters have the node of them.
+	for i := 0; i < len(da.bc) && d.err == nil; i++ {
+		if !da.bc[i].hasParams {
+			continue
+		}
+		if nd := da.node[i]; nd == nil || len(nd.paramTrees) == 0 && nd.wildcardTree == nil {
+			d.err = errors.New("doublearray: broken node index in binary format")
+		}
+	}
+	return da
+}
@@ -0,0 +1,340 @@
+package doublearray
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+type intCodec struct{}
+
+func (c intCodec) EncodeValue(v interface{}) ([]byte, error) {
+	return []byte(strconv.Itoa(v.(int))), nil
+}
+
+func (c intCodec) DecodeValue(data []byte) (interface{}, error) {
+	return strconv.Atoi(string(data))
+}
+
+func Test_DoubleArray_MarshalBinary(t *testing.T) {
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/", 0),
+		urlrouter.NewRecord("/path/to/route", 1),
+		urlrouter.NewRecord("/path/to/other", 2),
+		urlrouter.NewRecord("/path/to/:param", 3),
+		urlrouter.NewRecord("/path/to/wildcard/*routepath", 4),
+		urlrouter.NewRecord("/path/to/:param1/:param2", 5),
+		urlrouter.NewRecord("/:year/:month/:day", 6),
+		urlrouter.NewRecord("/a/to/b/:param/*routepath", 7),
+		urlrouter.NewRecord("/files/:name.:ext", 8),
+		urlrouter.NewRecord("/user/:id<int>", 10),
+		urlrouter.NewRecord("/user/:name", 11),
+		{Key: "/:lang/help", Value: 12, Priority: 1},
+	}
+	paths := []string{
+		"/", "/path/to/route", "/path/to/other", "/path/to/hoge", "/path/to/wildcard/some/params",
+		"/path/to/o1/o2", "/2014/01/06", "/a/to/b/p1/some/params", "/files/a.png", "/missing", "/path/to",
+		"/user/1", "/user/alice", "/user/help",
+	}
+	for _, codec := range []ValueCodec{nil, GobCodec{}, intCodec{}} {
+		da := New()
+		da.Codec = codec
+		if err := da.Build(records); err != nil {
+			t.Fatal(err)
+		}
+		data, err := da.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		actualDA := New()
+		actualDA.Codec = codec
+		if err := actualDA.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range paths {
+			actual, actualParams := actualDA.Lookup(path)
+			expected, expectedParams := da.Lookup(path)
+			if !reflect.DeepEqual(actual, expected) || !reflect.DeepEqual(actualParams, expectedParams) {
+				t.Errorf("codec = %T; Lookup(%q) expect %v %v, but %v %v", codec, path, expected, expectedParams, actual, actualParams)
+			}
+		}
+
+		// the decoded table must be able to be updated.
+		if err := actualDA.Add(urlrouter.NewRecord("/path/to/added", 9)); err != nil {
+			t.Fatal(err)
+		}
+		if actual, _ := actualDA.Lookup("/path/to/added"); actual != 9 {
+			t.Errorf("codec = %T; Lookup(%q) expect %v, but %v", codec, "/path/to/added", 9, actual)
+		}
+	}
+}
+
+func Test_DoubleArray_MarshalBinary_withOptions(t *testing.T) {
+	opts := urlrouter.Options{CaseInsensitive: true, Normalize: true, ShortestWildcard: true}
+	da := New()
+	da.SetOptions(opts)
+	if err := da.Build([]urlrouter.Record{
+		urlrouter.NewRecord("/Users/:Name", 0),
+		urlrouter.NewRecord("/caf\u00e9/:name", 1),
+		urlrouter.NewRecord("/Files/*Path/Edit/:name", 3),
+		urlrouter.NewRecord("/Files/*Path/:action", 4),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := da.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actualDA := New()
+	if err := actualDA.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actualDA.opts, opts) {
+		t.Errorf("Expect %v, but %v", opts, actualDA.opts)
+	}
+	for _, path := range []string{"/USERS/Alice", "/CAFE%CC%81/a%20b", "/FILES/a/EDIT/b"} {
+		actual, actualParams := actualDA.Lookup(path)
+		expected, expectedParams := da.Lookup(path)
+		if !reflect.DeepEqual(actual, expected) || !reflect.DeepEqual(actualParams, expectedParams) {
+			t.Errorf("Lookup(%q) expect %v %v, but %v %v", path, expected, expectedParams, actual, actualParams)
+		}
+	}
+
+	// keys added after decoding must be prepared by the decoded options.
+	if err := actualDA.Add(urlrouter.NewRecord("/Path/To/Added", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if actual, _ := actualDA.Lookup("/PATH/to/added"); actual != 2 {
+		t.Errorf("Lookup(%q) expect %v, but %v", "/PATH/to/added", 2, actual)
+	}
+}
+
+func Test_DoubleArray_MarshalBinary_withSyntax(t *testing.T) {
+	syntax, err := urlrouter.NewSyntax('+', '#', "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	da := New()
+	da.SetOptions(urlrouter.Options{Syntax: syntax})
+	if err := da.Build([]urlrouter.Record{
+		urlrouter.NewRecord("sensors/+id/temp", 0),
+		urlrouter.NewRecord("sensors/#rest", 1),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := da.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actualDA := New()
+	if err := actualDA.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	var actual, expected interface{} = actualDA.opts.SyntaxOrDefault().String(), syntax.String()
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	if err := actualDA.Add(urlrouter.NewRecord("devices/+id", 2)); err != nil {
+		t.Fatal(err)
+	}
+	for path, expected := range map[string]interface{}{"sensors/a.b/temp": 0, "sensors/a/b": 1, "devices/a.b": 2} {
+		if actual, _ := actualDA.Lookup(path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Lookup(%q) expect %v, but %v", path, expected, actual)
+		}
+	}
+
+	// the default syntax is decoded as nil.
+	da = New()
+	if err := da.Build([]urlrouter.Record{urlrouter.NewRecord("/user/:id", 0)}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = da.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	if err := actualDA.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	actual, expected = actualDA.opts, urlrouter.Options{}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_DoubleArray_UnmarshalBinary_withBrokenData(t *testing.T) {
+	da := New()
+	if err := da.Build([]urlrouter.Record{
+		urlrouter.NewRecord("/path/to/route", "testroute0"),
+		urlrouter.NewRecord("/user/:id", "testroute1"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := da.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{
+		nil,
+		[]byte("KUDB\x01"),
+		append([]byte(binaryMagic), 0xff, 0x01),
+		data[:len(data)/2],
+		data[:len(data)-1],
+	} {
+		actual := New()
+		if err := actual.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary(%q) expect error, but nil", data)
+		}
+	}
+}
+
+func Test_DoubleArray_MarshalBinary_withDeepTrees(t *testing.T) {
+	defer func(depth int) { maxTreeDepth = depth }(maxTreeDepth)
+	maxTreeDepth = 4
+	da := New()
+	if err := da.Build([]urlrouter.Record{urlrouter.NewRecord("/:a/:b/*c", "testroute0")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := da.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	if err := da.Build([]urlrouter.Record{urlrouter.NewRecord("/:a/:b/:c/*d", "testroute0")}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := da.MarshalBinary()
+	var actual interface{} = err
+	var expected interface{} = errTooDeepTrees
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	// a binary that is nested deeper than the limit isn't read.
+	maxTreeDepth = 5
+	data, err := da.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxTreeDepth = 4
+	actual = New().UnmarshalBinary(data)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+// brokenBinaryRecords are the records of the binary format that is mutated by the tests of broken data.
+func brokenBinaryRecords() []urlrouter.Record {
+	return []urlrouter.Record{
+		urlrouter.NewRecord("/path/to/route", 0),
+		urlrouter.NewRecord("/user/:id<int>", 1),
+		urlrouter.NewRecord("/user/:name.:ext", 2),
+		urlrouter.NewRecord("/static/*filepath/edit", 3),
+		urlrouter.NewRecord("/static/*filepath", 4),
+	}
+}
+
+// lookupBrokenBinary decodes data and looks up the decoded table if it is decoded.
+// The broken data must be reported by an error, and never cause a panic of the lookup.
+func lookupBrokenBinary(data []byte) {
+	da := New()
+	da.Codec = intCodec{}
+	if err := da.UnmarshalBinary(data); err != nil {
+		return
+	}
+	for _, path := range []string{"/", "/path/to/route", "/user/1", "/user/a.png", "/static/a/b/edit", "/static/a", "/missing"} {
+		da.Lookup(path)
+	}
+	da.Walk(func(key string, value interface{}) error { return nil })
+	da.Dump(io.Discard, urlrouter.DumpText)
+	da.Stats()
+}
+
+func Test_DoubleArray_UnmarshalBinary_withMutatedData(t *testing.T) {
+	da := New()
+	da.Codec = intCodec{}
+	if err := da.Build(brokenBinaryRecords()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := da.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range data {
+		for _, mask := range []byte{0x01, 0x02, 0x80, 0xff} {
+			mutated := append([]byte(nil), data...)
+			mutated[i] ^= mask
+			func() {
+				defer func() {
+					if err := recover(); err != nil {
+						t.Errorf("byte %d ^ %#x: panic: %v", i, mask, err)
+					}
+				}()
+				lookupBrokenBinary(mutated)
+			}()
+		}
+	}
+
+	// a slot that has path parameters must have the node of them.
+	broken := New()
+	if err := broken.Build(brokenBinaryRecords()); err != nil {
+		t.Fatal(err)
+	}
+	for idx, bc := range broken.static.bc {
+		if bc.check >= 0 && !bc.hasParams && broken.static.node[idx] == nil {
+			broken.static.bc[idx].hasParams = true
+			break
+		}
+	}
+	if data, err = broken.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	var actual interface{} = New().UnmarshalBinary(data)
+	var expected interface{} = errors.New("doublearray: broken node index in binary format")
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	da := New()
+	da.Codec = intCodec{}
+	if err := da.Build(brokenBinaryRecords()); err != nil {
+		f.Fatal(err)
+	}
+	data, err := da.MarshalBinary()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lookupBrokenBinary(data)
+	})
+}
+
+func Test_DoubleArray_WriteTo(t *testing.T) {
+	da := New()
+	if err := da.Build([]urlrouter.Record{urlrouter.NewRecord("/user/:id", "testroute0")}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	n, err := da.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int64(buf.Len())
+	var actual interface{} = n
+	var expected interface{} = size
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	n, err = New().ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual = n
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
@@ -2,15 +2,16 @@
 package doublearray
 
 import (
-	"fmt"
+	"math/bits"
 	"sort"
-	"strings"
 
 	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/match"
 )
 
 const (
-	// Block size of array of BASE/CHECK of Double-Array.
+	// Initial size of array of BASE/CHECK of the top-level Double-Arrays.
+	// The nested Double-Arrays of path parameters start with only the root because most of them are small.
 	blockSize = 256
 )
 
@@ -23,8 +24,17 @@ type baseCheck struct {
 
 // DoubleArray represents a URLRouter by Double-Array.
 type DoubleArray struct {
+	// Codec is used to encode and decode the values of records in the binary format.
+	// If it is nil, GobCodec will be used.
+	Codec ValueCodec
+
 	static *doubleArray
 	param  *doubleArray
+
+	// Maximum priority of the records.
+	maxPriority int
+
+	opts urlrouter.Options
 }
 
 // New returns a new DoubleArray.
@@ -38,6 +48,16 @@ func New() *DoubleArray {
 type doubleArray struct {
 	bc   []baseCheck
 	node map[int]*node
+
+	// Bitmap of the used slots of bc to find the empty slots quickly.
+	// It is built from bc when it's needed, and nil until then.
+	used []uint64
+
+	// Index where the search of the empty slots starts. All slots before it are used.
+	firstEmpty int
+
+	// Constraint of the path parameter that leads to this tree.
+	constraint *urlrouter.Constraint
 }
 
 func newDoubleArray(size int) *doubleArray {
@@ -58,42 +78,148 @@ func newBaseCheckArray(size int) []baseCheck {
 
 // Lookup returns result data of lookup from Double-Array routing table by given path.
 func (da *DoubleArray) Lookup(path string) (data interface{}, params []urlrouter.Param) {
-	if idx, found := da.static.lookupStatic(path); found {
-		return da.static.node[idx].data, nil
+	return da.LookupInto(path, nil)
+}
+
+// LookupInto is the same as Lookup, but path parameters are appended to dst[:0].
+// It doesn't allocate if the capacity of dst is enough to hold path parameters and
+// all records that match path have the maximum Priority in the routing table.
+func (da *DoubleArray) LookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	data, params, _ = urlrouter.LookupFixed(path, da.opts.TrailingSlash, dst, da.lookupInto)
+	return data, params
+}
+
+// LookupTrailingSlash implements the urlrouter.TrailingSlashLookuper.
+func (da *DoubleArray) LookupTrailingSlash(path string) (data interface{}, params []urlrouter.Param, fixed string) {
+	return urlrouter.LookupFixed(path, da.opts.TrailingSlash, nil, da.lookupInto)
+}
+
+// lookupInto looks up path as it is.
+func (da *DoubleArray) lookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	if da.opts.Normalize {
+		path = urlrouter.NormalizePath(path)
 	}
-	nodes, idx, values := da.param.lookupParam(path, nil)
-	if nodes == nil {
-		return nil, nil
+	m := match.New[*node](da.maxPriority, da.opts)
+	if idx, found := da.static.lookupStatic(path, m.CaseInsensitive()); found {
+		if nd := da.static.node[idx]; nd != nil && m.Match(nd, nd.priority, dst[:0]) {
+			return nd.data, dst[:0]
+		}
 	}
-	nd := nodes[idx]
-	if nd == nil {
-		return nil, nil
+	da.param.lookupParam(path, dst[:0], &m)
+	nd, matched, found := m.Result()
+	if !found {
+		return nil, dst[:0]
 	}
-	if len(values) > 0 {
-		params = make([]urlrouter.Param, len(values))
-		for i, v := range values {
-			params[i] = urlrouter.Param{Name: nd.paramNames[i], Value: v}
-		}
+	params = append(dst[:0], matched...)
+	for i := range params {
+		params[i].Name = nd.paramNames[i]
 	}
 	return nd.data, params
 }
 
+// SetOptions implements the urlrouter.Configurable.
+func (da *DoubleArray) SetOptions(opts urlrouter.Options) {
+	da.opts = opts
+}
+
 // Build builds Double-Array routing table from records.
+// Optional segments of keys are expanded by urlrouter.ExpandRecords.
 func (da *DoubleArray) Build(records []urlrouter.Record) error {
-	statics, params := makeRecords(records)
-	if err := da.static.build(statics, 0, 0); err != nil {
+	s := da.opts.SyntaxOrDefault()
+	if err := da.opts.CheckDuplicateKeys(records); err != nil {
+		return err
+	}
+	records, err := s.ExpandRecords(records)
+	if err != nil {
+		return err
+	}
+	records = urlrouter.NormalizeRecords(records, da.opts)
+	da.static, da.param = newDoubleArray(blockSize), newDoubleArray(blockSize)
+	da.maxPriority = urlrouter.MaxPriority(records)
+	statics, params := makeRecords(s, records)
+	if err := da.static.build(s, statics, 0, 0); err != nil {
 		return err
 	}
-	if err := da.param.build(params, 0, 0); err != nil {
+	if err := da.param.build(s, params, 0, 0); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (da *doubleArray) lookupStatic(path string) (idx int, found bool) {
+// Add adds a record to the built Double-Array routing table without rebuilding the whole of it.
+// If the key of record already exists, its value will be replaced.
+// If the key of record has optional segments, all of the expanded keys will be added.
+func (da *DoubleArray) Add(record urlrouter.Record) error {
+	s := da.opts.SyntaxOrDefault()
+	records, err := s.ExpandRecords([]urlrouter.Record{record})
+	if err != nil {
+		return err
+	}
+	records = urlrouter.NormalizeRecords(records, da.opts)
+	if record.Priority > da.maxPriority {
+		da.maxPriority = record.Priority
+	}
+	for _, record := range records {
+		if s.HasMetaChar(record.Key) {
+			err = da.param.add(s, &Record{Record: record}, 0, 0)
+		} else {
+			err = da.static.add(s, &Record{Record: record}, 0, 0)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove removes a record of key from the built Double-Array routing table.
+// If key has optional segments, all of the expanded keys will be removed.
+// It reports whether any record was removed.
+func (da *DoubleArray) Remove(key string) bool {
+	s := da.opts.SyntaxOrDefault()
+	records, err := s.ExpandRecords([]urlrouter.Record{{Key: key}})
+	if err != nil {
+		return false
+	}
+	removed := false
+	for _, record := range urlrouter.NormalizeRecords(records, da.opts) {
+		if s.HasMetaChar(record.Key) {
+			removed = da.param.remove(s, record.Key, 0, nil) || removed
+		} else {
+			removed = da.static.remove(s, record.Key, 0, nil) || removed
+		}
+	}
+	return removed
+}
+
+// Walk implements the urlrouter.Walker.
+// The static routes are walked in the order of keys, and then the routes that have path parameters are walked in
+// the order of the tree, that is, the routes of path parameters follow the routes that share the prefix of them.
+func (da *DoubleArray) Walk(fn func(key string, value interface{}) error) error {
+	return da.walk(func(key string, nd *node) error {
+		return fn(key, nd.data)
+	})
+}
+
+// walk calls fn for the nodes of the routes of the static Double-Array and the Double-Array of path parameters.
+func (da *DoubleArray) walk(fn func(key string, nd *node) error) error {
+	s := da.opts.SyntaxOrDefault()
+	if err := da.static.walk(s, 0, nil, nil, nil, fn); err != nil {
+		return err
+	}
+	return da.param.walk(s, 0, nil, nil, nil, fn)
+}
+
+// lookupStatic returns an index of the node that matches path.
+// If caseInsensitive is true, upper-case ASCII letters of path are folded.
+func (da *doubleArray) lookupStatic(path string, caseInsensitive bool) (idx int, found bool) {
 	for i := 0; i < len(path); i++ {
-		next := nextIndex(da.bc[idx].base, path[i])
-		if da.bc[next].check != idx {
+		c := path[i]
+		if caseInsensitive {
+			c = urlrouter.FoldByte(c)
+		}
+		next := nextIndex(da.bc[idx].base, c)
+		if next >= len(da.bc) || da.bc[next].check != idx {
 			return -1, false
 		}
 		idx = next
@@ -101,181 +227,572 @@ func (da *doubleArray) lookupStatic(path string) (idx int, found bool) {
 	return idx, true
 }
 
-func (da *doubleArray) lookupParam(path string, params []string) (map[int]*node, int, []string) {
+// lookupParam looks up the nodes that match path, and passes them to m with params that values of path parameters
+// are appended. Names of params aren't set. It reports whether m stopped the lookup.
+func (da *doubleArray) lookupParam(path string, params []urlrouter.Param, m *match.Matcher[*node]) bool {
 	idx := 0
-	var indexes []int64
-	for i := 0; i < len(path); i++ {
-		next := nextIndex(da.bc[idx].base, path[i])
-		if da.bc[next].check != idx {
-			goto PARAMED_ROUTE
+	var buf [8]int64
+	indexes := buf[:0]
+	if da.bc[idx].hasParams {
+		indexes = append(indexes, 0)
+	}
+	i := 0
+	for ; i < len(path); i++ {
+		c := path[i]
+		if m.CaseInsensitive() {
+			c = urlrouter.FoldByte(c)
+		}
+		next := nextIndex(da.bc[idx].base, c)
+		if next >= len(da.bc) || da.bc[next].check != idx {
+			break
 		}
 		idx = next
 		if da.bc[idx].hasParams {
 			indexes = append(indexes, int64(((i+1)&0xffffffff)<<32)|int64(idx&0xffffffff))
 		}
 	}
-	return da.node, idx, params
-PARAMED_ROUTE:
+	if i == len(path) {
+		if nd := da.node[idx]; nd != nil && nd.data != nil && m.Match(nd, nd.priority, params) {
+			return true
+		}
+	}
 	for i := len(indexes) - 1; i >= 0; i-- {
 		curIdx, idx := int((indexes[i]>>32)&0xffffffff), int(indexes[i]&0xffffffff)
 		nd := da.node[idx]
-		if nd.paramTree != nil {
-			i := urlrouter.NextSeparator(path, curIdx)
-			remaining, params := path[i:], append(params, path[curIdx:i])
-			if nodes, idx, params := nd.paramTree.lookupParam(remaining, params); nodes != nil {
-				return nodes, idx, params
+		for _, tree := range nd.paramTrees {
+			var buf [8]int
+			for _, end := range m.Syntax().ParamEnds(buf[:0], path, curIdx, m.CaseInsensitive(), tree.hasRootChild) {
+				value := path[curIdx:end]
+				if tree.constraint != nil && !tree.constraint.Match(value) {
+					continue
+				}
+				if tree.lookupParam(path[end:], append(params, urlrouter.Param{Value: value}), m) {
+					return true
+				}
 			}
 		}
-		if nd.wildcardTree != nil {
-			return nd.wildcardTree.node, 0, append(params, path[curIdx:])
+		if tree := nd.wildcardTree; tree != nil && curIdx < len(path) {
+			var buf [8]int
+			for _, end := range urlrouter.WildcardEnds(buf[:0], path, curIdx, m.CaseInsensitive(), m.ShortestWildcard(), tree.hasRootChild) {
+				if tree.lookupParam(path[end:], append(params, urlrouter.Param{Value: path[curIdx:end]}), m) {
+					return true
+				}
+			}
+			// the key that ends with the wildcard path parameter is the last resort.
+			if leaf := tree.node[0]; leaf != nil && leaf.data != nil && m.Match(leaf, leaf.priority, append(params, urlrouter.Param{Value: path[curIdx:]})) {
+				return true
+			}
 		}
 	}
-	return nil, -1, nil
+	return false
 }
 
-func (da *doubleArray) build(srcs []*Record, idx, depth int) error {
-	base, siblings, leaf, err := da.arrange(srcs, idx, depth)
-	if err != nil {
-		return err
+// walk calls fn for the nodes of the routes of idx and its descendants.
+// prefix is the static part of the key after the last path parameter, and statics and params are the parts of
+// the key before it. (see urlrouter.JoinKey)
+func (da *doubleArray) walk(s *urlrouter.Syntax, idx int, prefix []byte, statics, params []string, fn func(key string, nd *node) error) error {
+	nd := da.node[idx]
+	if nd != nil && nd.data != nil {
+		if err := fn(urlrouter.JoinKey(append(statics[:len(statics):len(statics)], string(prefix)), params, nd.paramNames), nd); err != nil {
+			return err
+		}
 	}
-	if leaf != nil {
-		nd, err := makeNode(leaf)
-		if err != nil {
+	for _, c := range da.children(idx) {
+		if err := da.walk(s, nextIndex(da.bc[idx].base, c), append(prefix[:len(prefix):len(prefix)], c), statics, params, fn); err != nil {
 			return err
 		}
-		da.node[idx] = nd
+	}
+	if nd == nil {
+		return nil
+	}
+	statics = append(statics[:len(statics):len(statics)], string(prefix))
+	for _, tree := range nd.paramTrees {
+		if err := tree.walk(s, 0, nil, statics, append(params[:len(params):len(params)], tree.paramKey(s)), fn); err != nil {
+			return err
+		}
+	}
+	if nd.wildcardTree != nil {
+		return nd.wildcardTree.walk(s, 0, nil, statics, append(params[:len(params):len(params)], string(s.WildcardChar())), fn)
+	}
+	return nil
+}
+
+// paramKey returns the path parameter that leads to the tree without the name such as ":" and ":<int>".
+func (da *doubleArray) paramKey(s *urlrouter.Syntax) string {
+	if da.constraint == nil {
+		return string(s.ParamChar())
+	}
+	return string(s.ParamChar()) + da.constraint.String()
+}
+
+func (da *doubleArray) build(s *urlrouter.Syntax, srcs []*Record, idx, depth int) error {
+	// most nodes have only one child, the buffer saves the allocation for them.
+	var buf [1]sibling
+	base, siblings, leaf := da.arrange(s, srcs, idx, depth, buf[:0])
+	if leaf != nil {
+		da.node[idx] = makeNode(leaf)
 	}
 	for _, sib := range siblings {
-		if !urlrouter.IsMetaChar(sib.c) {
+		if !sib.meta {
 			da.setCheck(nextIndex(base, sib.c), idx)
 		}
 	}
 	for _, sib := range siblings {
-		switch records := srcs[sib.start:sib.end]; sib.c {
-		case urlrouter.ParamCharacter:
+		switch records := srcs[sib.start:sib.end]; {
+		case !sib.meta:
+			if err := da.build(s, records, nextIndex(base, sib.c), depth+1); err != nil {
+				return err
+			}
+		case sib.c == s.ParamChar():
+			constraints := make(map[string][]*Record)
 			for _, record := range records {
-				next := urlrouter.NextSeparator(record.Key, depth)
-				name := record.Key[depth+1 : next]
+				name, constraint, next, err := s.ParseParam(record.Key, depth)
+				if err != nil {
+					return err
+				}
 				record.paramNames = append(record.paramNames, name)
 				record.Key = record.Key[next:]
+				constraints[constraint] = append(constraints[constraint], record)
 			}
-			if da.node[idx] == nil {
-				da.node[idx] = &node{}
+			nd := da.nodeOf(idx)
+			for constraint, records := range constraints {
+				tree, err := nd.paramTree(constraint)
+				if err != nil {
+					return err
+				}
+				sort.Sort(RecordSlice(records))
+				if err := tree.build(s, records, 0, 0); err != nil {
+					return err
+				}
 			}
-			da.node[idx].paramTree = newDoubleArray(blockSize)
 			da.bc[idx].hasParams = true
-			if err := da.node[idx].paramTree.build(records, 0, 0); err != nil {
+		default:
+			for _, record := range records {
+				name, _, next, err := s.ParseParam(record.Key, depth)
+				if err != nil {
+					return err
+				}
+				record.paramNames = append(record.paramNames, name)
+				record.Key = record.Key[next:]
+			}
+			tree := newDoubleArray(1)
+			da.nodeOf(idx).wildcardTree = tree
+			sort.Sort(RecordSlice(records))
+			if err := tree.build(s, records, 0, 0); err != nil {
 				return err
 			}
-		case urlrouter.WildcardCharacter:
-			if da.node[idx] == nil {
-				da.node[idx] = &node{}
+			da.bc[idx].hasParams = true
+		}
+	}
+	return nil
+}
+
+// add adds a record to Double-Array.
+// It relocates the siblings if the place of a new node is already used.
+func (da *doubleArray) add(s *urlrouter.Syntax, record *Record, idx, depth int) error {
+	for ; depth < len(record.Key); depth++ {
+		switch c := record.Key[depth]; c {
+		case s.ParamChar():
+			name, constraint, next, err := s.ParseParam(record.Key, depth)
+			if err != nil {
+				return err
 			}
-			record := records[0]
-			name := record.Key[depth+1:]
 			record.paramNames = append(record.paramNames, name)
-			da.node[idx].wildcardTree = newDoubleArray(0)
-			nd, err := makeNode(record)
+			record.Key = record.Key[next:]
+			tree, err := da.nodeOf(idx).paramTree(constraint)
 			if err != nil {
 				return err
 			}
-			da.node[idx].wildcardTree.node[0] = nd
 			da.bc[idx].hasParams = true
-		default:
-			if err := da.build(records, nextIndex(base, sib.c), depth+1); err != nil {
+			return tree.add(s, record, 0, 0)
+		case s.WildcardChar():
+			name, _, next, err := s.ParseParam(record.Key, depth)
+			if err != nil {
 				return err
 			}
+			record.paramNames = append(record.paramNames, name)
+			record.Key = record.Key[next:]
+			nd := da.nodeOf(idx)
+			if nd.wildcardTree == nil {
+				nd.wildcardTree = newDoubleArray(1)
+			}
+			da.bc[idx].hasParams = true
+			return nd.wildcardTree.add(s, record, 0, 0)
+		default:
+			idx = da.child(idx, c)
 		}
 	}
+	leaf := makeNode(record)
+	nd := da.nodeOf(idx)
+	nd.data, nd.priority, nd.paramNames = leaf.data, leaf.priority, leaf.paramNames
 	return nil
 }
 
+// remove removes a record of key from Double-Array.
+// names are the path parameter names that appeared in the parent trees.
+// It reports whether the record was removed.
+func (da *doubleArray) remove(s *urlrouter.Syntax, key string, idx int, names []string) bool {
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case s.ParamChar():
+			nd := da.node[idx]
+			if nd == nil {
+				return false
+			}
+			name, constraint, next, err := s.ParseParam(key, i)
+			if err != nil {
+				return false
+			}
+			j := nd.paramTreeIndex(constraint)
+			if j < 0 || !nd.paramTrees[j].remove(s, key[next:], 0, append(names, name)) {
+				return false
+			}
+			if nd.paramTrees[j].isEmpty() {
+				nd.paramTrees = append(nd.paramTrees[:j], nd.paramTrees[j+1:]...)
+				da.prune(idx)
+			}
+			return true
+		case s.WildcardChar():
+			nd := da.node[idx]
+			if nd == nil || nd.wildcardTree == nil {
+				return false
+			}
+			name, _, next, err := s.ParseParam(key, i)
+			if err != nil || !nd.wildcardTree.remove(s, key[next:], 0, append(names, name)) {
+				return false
+			}
+			if nd.wildcardTree.isEmpty() {
+				nd.wildcardTree = nil
+				da.prune(idx)
+			}
+			return true
+		default:
+			next := nextIndex(da.bc[idx].base, c)
+			if next >= len(da.bc) || da.bc[next].check != idx {
+				return false
+			}
+			idx = next
+		}
+	}
+	nd := da.node[idx]
+	if nd == nil || nd.data == nil || !equalNames(nd.paramNames, names) {
+		return false
+	}
+	nd.data, nd.priority, nd.paramNames = nil, 0, nil
+	da.prune(idx)
+	return true
+}
+
+// prune removes the node of idx and its ancestors that no longer lead to any record.
+func (da *doubleArray) prune(idx int) {
+	for {
+		if nd := da.node[idx]; nd != nil {
+			da.bc[idx].hasParams = len(nd.paramTrees) > 0 || nd.wildcardTree != nil
+			if nd.data != nil || da.bc[idx].hasParams {
+				return
+			}
+			delete(da.node, idx)
+		}
+		if idx == 0 || len(da.children(idx)) > 0 {
+			return
+		}
+		parent := da.bc[idx].check
+		da.free(idx)
+		idx = parent
+	}
+}
+
+// isEmpty returns whether Double-Array has no records.
+func (da *doubleArray) isEmpty() bool {
+	return len(da.node) == 0 && len(da.children(0)) == 0
+}
+
+// nodeOf returns the node of idx. A new node will be created if it doesn't exist.
+func (da *doubleArray) nodeOf(idx int) *node {
+	nd := da.node[idx]
+	if nd == nil {
+		nd = &node{}
+		da.node[idx] = nd
+	}
+	return nd
+}
+
+// hasRootChild returns whether the root has the child node of c.
+func (da *doubleArray) hasRootChild(c byte) bool {
+	next := nextIndex(da.bc[0].base, c)
+	return next < len(da.bc) && next != 0 && da.bc[next].check == 0
+}
+
+// children returns characters of the child nodes of idx in ascending order.
+func (da *doubleArray) children(idx int) (cs []byte) {
+	base := da.bc[idx].base
+	for c := 0; c <= 0xff; c++ {
+		if next := nextIndex(base, byte(c)); next < len(da.bc) && next != idx && da.bc[next].check == idx {
+			cs = append(cs, byte(c))
+		}
+	}
+	return cs
+}
+
+// child returns an index of the child node of idx by c.
+// A new child node will be added if it doesn't exist.
+func (da *doubleArray) child(idx int, c byte) int {
+	base := da.bc[idx].base
+	switch cs := da.children(idx); {
+	case len(cs) == 0:
+		base = da.findBase([]sibling{{c: c}})
+		da.setBase(idx, base)
+	default:
+		next := nextIndex(base, c)
+		if next < len(da.bc) && da.bc[next].check == idx {
+			return next
+		}
+		if next < len(da.bc) && da.isUsed(next) {
+			base = da.relocate(idx, append(cs, c))
+		}
+	}
+	next := nextIndex(base, c)
+	da.setCheck(next, idx)
+	return next
+}
+
+// relocate moves the child nodes of idx to the place where all of cs can be placed, and returns a new BASE of idx.
+// The last character of cs is for a new child node that isn't placed yet.
+func (da *doubleArray) relocate(idx int, cs []byte) int {
+	siblings := make([]sibling, len(cs))
+	for i, c := range cs {
+		siblings[i].c = c
+	}
+	oldBase, base := da.bc[idx].base, da.findBase(siblings)
+	for _, c := range cs[:len(cs)-1] {
+		from, to := nextIndex(oldBase, c), nextIndex(base, c)
+		da.extendBaseCheckArray(to)
+		da.bc[to] = da.bc[from]
+		da.setUsed(to, true)
+		for _, gc := range da.children(from) {
+			da.setCheck(nextIndex(da.bc[from].base, gc), to)
+		}
+		if nd, exists := da.node[from]; exists {
+			da.node[to] = nd
+			delete(da.node, from)
+		}
+		da.free(from)
+	}
+	da.setBase(idx, base)
+	return base
+}
+
 // setBase sets BASE.
 func (da *doubleArray) setBase(i, base int) {
 	da.bc[i].base = base
 }
 
-// setCheck sets CHECK.
+// setCheck sets CHECK. The array of BASE/CHECK will be extended if i is out of it.
 func (da *doubleArray) setCheck(i, check int) {
+	da.extendBaseCheckArray(i)
 	da.bc[i].check = check
+	da.setUsed(i, check >= 0)
 }
 
-// extendBaseCheckArray extends array of BASE/CHECK.
-func (da *doubleArray) extendBaseCheckArray() {
-	da.bc = append(da.bc, newBaseCheckArray(blockSize)...)
+// free makes the slot of i empty.
+func (da *doubleArray) free(i int) {
+	da.bc[i] = baseCheck{check: -1}
+	da.setUsed(i, false)
 }
 
-// findEmptyIndex returns an index of unused BASE/CHECK node.
-func (da *doubleArray) findEmptyIndex(start int) int {
-	i := start
-	for ; i < len(da.bc); i++ {
-		if da.bc[i].base == 0 && da.bc[i].check == -1 {
-			break
+// setUsed updates the bitmap of the used slots by the slot of i if the bitmap has been built.
+func (da *doubleArray) setUsed(i int, used bool) {
+	if da.used == nil {
+		return
+	}
+	if used {
+		da.used[i/64] |= 1 << uint(i%64)
+		return
+	}
+	da.used[i/64] &^= 1 << uint(i%64)
+	if i < da.firstEmpty {
+		da.firstEmpty = i
+	}
+}
+
+// buildUsed builds the bitmap of the used slots from BASE/CHECK.
+func (da *doubleArray) buildUsed() {
+	da.used, da.firstEmpty = make([]uint64, (len(da.bc)+63)/64), 0
+	for i := range da.bc {
+		if da.isUsed(i) {
+			da.used[i/64] |= 1 << uint(i%64)
 		}
 	}
-	return i
 }
 
-// findBase returns good BASE.
-func (da *doubleArray) findBase(siblings []sibling, start int) (base int) {
-	idx := start + 1
-	firstChar := siblings[0].c
-	for ; idx < len(da.bc); idx = da.findEmptyIndex(idx + 1) {
-		base = nextIndex(idx, firstChar)
-		i := 0
-		for ; i < len(siblings); i++ {
-			if urlrouter.IsMetaChar(siblings[i].c) {
-				continue
-			}
-			if next := nextIndex(base, siblings[i].c); da.bc[next].base != 0 || da.bc[next].check != -1 {
-				break
+// extendBaseCheckArray extends array of BASE/CHECK to hold the slot of i.
+func (da *doubleArray) extendBaseCheckArray(i int) {
+	n := len(da.bc)
+	if i < n {
+		return
+	}
+	if i >= cap(da.bc) {
+		// the capacity is doubled so that slots can be added one by one without copying every time.
+		bc := make([]baseCheck, n, 2*i+1)
+		copy(bc, da.bc)
+		da.bc = bc
+	}
+	da.bc = da.bc[:i+1]
+	for j := n; j <= i; j++ {
+		da.bc[j] = baseCheck{check: -1}
+	}
+	if da.used != nil {
+		for len(da.used) < (len(da.bc)+63)/64 {
+			da.used = append(da.used, 0)
+		}
+	}
+}
+
+// nextEmpty returns an index of the first empty slot at or after start.
+// All slots after the end of the array of BASE/CHECK are empty.
+// The bitmap of the used slots must have been built.
+func (da *doubleArray) nextEmpty(start int) int {
+	for w := start / 64; w < len(da.used); w++ {
+		empty := ^da.used[w]
+		if w == start/64 {
+			empty &= ^uint64(0) << uint(start%64)
+		}
+		if empty != 0 {
+			if i := w*64 + bits.TrailingZeros64(empty); i < len(da.bc) {
+				return i
 			}
+			break
+		}
+	}
+	if start > len(da.bc) {
+		return start
+	}
+	return len(da.bc)
+}
+
+// findBase returns a BASE where all of siblings can be placed in the empty slots.
+// The empty slots are tried from the first one in order, so the array is filled from the beginning.
+// Meta characters of siblings aren't placed. The array of BASE/CHECK will be extended by setCheck.
+func (da *doubleArray) findBase(siblings []sibling) int {
+	var anchor *sibling
+	for i := range siblings {
+		if !siblings[i].meta {
+			anchor = &siblings[i]
+			break
 		}
-		if i == len(siblings) {
+	}
+	if anchor == nil {
+		return 0
+	}
+	if da.used == nil {
+		da.buildUsed()
+	}
+	da.firstEmpty = da.nextEmpty(da.firstEmpty)
+	for idx := da.firstEmpty; ; idx = da.nextEmpty(idx + 1) {
+		base := nextIndex(idx, anchor.c)
+		if da.fits(base, siblings) {
 			return base
 		}
 	}
-	da.extendBaseCheckArray()
-	return nextIndex(idx, firstChar)
 }
 
-func (da *doubleArray) arrange(records []*Record, idx, depth int) (base int, siblings []sibling, leaf *Record, err error) {
-	siblings, leaf, err = makeSiblings(records, depth)
-	if err != nil {
-		return -1, nil, nil, err
+// fits returns whether all of siblings can be placed in the empty slots by base.
+func (da *doubleArray) fits(base int, siblings []sibling) bool {
+	for _, sib := range siblings {
+		if sib.meta {
+			continue
+		}
+		next := nextIndex(base, sib.c)
+		if next == 0 || next < len(da.bc) && da.used[next/64]&(1<<uint(next%64)) != 0 {
+			return false
+		}
 	}
+	return true
+}
+
+// arrange sets BASE of idx for the siblings of records at depth, and returns it with the siblings that appended to buf.
+func (da *doubleArray) arrange(s *urlrouter.Syntax, records []*Record, idx, depth int, buf []sibling) (base int, siblings []sibling, leaf *Record) {
+	siblings, leaf = makeSiblings(s, records, depth, buf)
 	if len(siblings) < 1 {
-		return -1, nil, leaf, nil
+		return -1, nil, leaf
 	}
-	base = da.findBase(siblings, idx)
+	base = da.findBase(siblings)
 	da.setBase(idx, base)
-	return base, siblings, leaf, err
+	return base, siblings, leaf
 }
 
 // node represents a node of Double-Array.
 type node struct {
-	data interface{}
+	data     interface{}
+	priority int
 
-	// Tree of path parameter.
-	paramTree *doubleArray
+	// Trees of path parameter.
+	// The trees that have a constraint are ordered by the constraint, and the tree that has no constraint is the last.
+	paramTrees []*doubleArray
 
-	// Tree of wildcard path parameter.
+	// Tree of wildcard path parameter. The root node is the leaf of the key that ends with the wildcard path parameter.
 	wildcardTree *doubleArray
 
 	// Names of path parameters.
 	paramNames []string
 }
 
+// paramTree returns the tree of path parameter that has constraint.
+// A new tree will be created if it doesn't exist.
+func (nd *node) paramTree(constraint string) (*doubleArray, error) {
+	if i := nd.paramTreeIndex(constraint); i >= 0 {
+		return nd.paramTrees[i], nil
+	}
+	tree := newDoubleArray(1)
+	if constraint != "" {
+		c, err := urlrouter.NewConstraint(constraint)
+		if err != nil {
+			return nil, err
+		}
+		tree.constraint = c
+	}
+	nd.insertParamTree(tree)
+	return tree, nil
+}
+
+// insertParamTree inserts tree into the trees of path parameter in order.
+func (nd *node) insertParamTree(tree *doubleArray) {
+	i := sort.Search(len(nd.paramTrees), func(i int) bool {
+		c := nd.paramTrees[i].constraint
+		return c == nil || tree.constraint != nil && c.String() > tree.constraint.String()
+	})
+	nd.paramTrees = append(nd.paramTrees, nil)
+	copy(nd.paramTrees[i+1:], nd.paramTrees[i:])
+	nd.paramTrees[i] = tree
+}
+
+// paramTreeIndex returns an index of the tree of path parameter that has constraint, or -1 if it doesn't exist.
+func (nd *node) paramTreeIndex(constraint string) int {
+	for i, tree := range nd.paramTrees {
+		if tree.constraint == nil && constraint == "" || tree.constraint != nil && tree.constraint.String() == constraint {
+			return i
+		}
+	}
+	return -1
+}
+
 // makeNode returns a new node from record.
-func makeNode(record *Record) (*node, error) {
-	dups := make(map[string]bool)
-	for _, name := range record.paramNames {
-		if dups[name] {
-			return nil, fmt.Errorf("path parameter `%v` is duplicated in the key '%v'", name, record.Key)
+// The names of path parameters of record have been checked by urlrouter.ExpandRecords.
+func makeNode(record *Record) *node {
+	return &node{data: record.Value, priority: record.Priority, paramNames: record.paramNames}
+}
+
+// equalNames returns whether a and b are the same path parameter names.
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
 		}
-		dups[name] = true
 	}
-	return &node{data: record.Value, paramNames: record.paramNames}, nil
+	return true
 }
 
 // sibling represents an intermediate data of build for Double-Array.
@@ -288,6 +805,9 @@ type sibling struct {
 
 	// A character of sibling.
 	c byte
+
+	// Whether c is a meta character. The siblings of meta characters aren't placed in the array of BASE/CHECK.
+	meta bool
 }
 
 // nextIndex returns a next index of array of BASE/CHECK.
@@ -295,12 +815,14 @@ func nextIndex(base int, c byte) int {
 	return base ^ int(c)
 }
 
-// makeSiblings returns slice of sibling.
-func makeSiblings(records []*Record, depth int) (sib []sibling, leaf *Record, err error) {
+// makeSiblings returns slice of sibling that appended to buf.
+// records must have been sorted by the keys.
+func makeSiblings(s *urlrouter.Syntax, records []*Record, depth int, buf []sibling) (sib []sibling, leaf *Record) {
 	var (
 		pc byte
 		n  int
 	)
+	sib = buf
 	for i, record := range records {
 		if len(record.Key) == depth {
 			leaf = record
@@ -308,12 +830,12 @@ func makeSiblings(records []*Record, depth int) (sib []sibling, leaf *Record, er
 		}
 		c := record.Key[depth]
 		switch {
-		case pc < c:
-			sib = append(sib, sibling{start: i, c: c})
+		case n == 0 || pc < c:
+			sib = append(sib, sibling{start: i, c: c, meta: s.IsMetaChar(c)})
 		case pc == c:
 			continue
 		default:
-			return nil, nil, fmt.Errorf("BUG: routing table hasn't been sorted")
+			panic("doublearray: BUG: records haven't been sorted")
 		}
 		if n > 0 {
 			sib[n-1].end = i
@@ -322,10 +844,10 @@ func makeSiblings(records []*Record, depth int) (sib []sibling, leaf *Record, er
 		n++
 	}
 	if n == 0 {
-		return nil, leaf, nil
+		return nil, leaf
 	}
 	sib[n-1].end = len(records)
-	return sib, leaf, nil
+	return sib, leaf
 }
 
 // Record represents a record that use to build the Double-Array.
@@ -338,10 +860,9 @@ type Record struct {
 type RecordSlice []*Record
 
 // makeRecords returns the records that use to build Double-Arrays.
-func makeRecords(srcs []urlrouter.Record) (statics, params []*Record) {
-	spChars := string([]byte{urlrouter.ParamCharacter, urlrouter.WildcardCharacter})
+func makeRecords(s *urlrouter.Syntax, srcs []urlrouter.Record) (statics, params []*Record) {
 	for _, record := range srcs {
-		if strings.ContainsAny(record.Key, spChars) {
+		if s.HasMetaChar(record.Key) {
 			params = append(params, &Record{Record: record})
 		} else {
 			statics = append(statics, &Record{Record: record})
@@ -1,6 +1,7 @@
 package doublearray
 
 import (
+	"os"
 	"testing"
 
 	"github.com/naoina/kocha-urlrouter/testutil"
@@ -18,6 +19,14 @@ func Benchmark_DoubleArray_Lookup_700(b *testing.B) {
 	testutil.Benchmark_URLRouter_Lookup(b, New(), 700)
 }
 
+func Benchmark_DoubleArray_LookupInto_static(b *testing.B) {
+	testutil.Benchmark_URLRouter_LookupInto(b, New(), "/path/to/route")
+}
+
+func Benchmark_DoubleArray_LookupInto_param(b *testing.B) {
+	testutil.Benchmark_URLRouter_LookupInto(b, New(), "/path/to/p1/sep/p2")
+}
+
 func Benchmark_DoubleArray_Build_100(b *testing.B) {
 	testutil.Benchmark_URLRouter_Build(b, &DoubleArrayRouter{}, 100)
 }
@@ -29,3 +38,19 @@ func Benchmark_DoubleArray_Build_300(b *testing.B) {
 func Benchmark_DoubleArray_Build_700(b *testing.B) {
 	testutil.Benchmark_URLRouter_Build(b, &DoubleArrayRouter{}, 700)
 }
+
+func Benchmark_DoubleArray_Build_10000(b *testing.B) {
+	testutil.Benchmark_URLRouter_Build(b, &DoubleArrayRouter{}, 10000)
+}
+
+func Benchmark_DoubleArray_Build_100000(b *testing.B) {
+	testutil.Benchmark_URLRouter_Build(b, &DoubleArrayRouter{}, 100000)
+}
+
+// Benchmark_DoubleArray_Build_1000000 takes a long time, so it runs only if URLROUTER_BENCH_LARGE is set.
+func Benchmark_DoubleArray_Build_1000000(b *testing.B) {
+	if os.Getenv("URLROUTER_BENCH_LARGE") == "" {
+		b.Skip("set URLROUTER_BENCH_LARGE to run the benchmark of a million routes")
+	}
+	testutil.Benchmark_URLRouter_Build(b, &DoubleArrayRouter{}, 1000000)
+}
@@ -1,9 +1,14 @@
 package doublearray
 
 import (
+	"bytes"
+	"fmt"
+	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/naoina/kocha-urlrouter"
 	"github.com/naoina/kocha-urlrouter/testutil"
 )
 
@@ -21,6 +26,55 @@ func Test_DoubleArray_Lookup(t *testing.T) {
 	testutil.Test_URLRouter_Lookup(t, &DoubleArrayRouter{})
 }
 
+func Test_DoubleArray_Lookup_with_constraints(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_constraints(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Lookup_with_params_in_segment(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_params_in_segment(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Lookup_with_precedence(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_precedence(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Lookup_with_optional_segments(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_optional_segments(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Lookup_with_options(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_options(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Lookup_with_trailing_slash(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_trailing_slash(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Lookup_with_syntax(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_syntax(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Lookup_with_mid_path_wildcards(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_mid_path_wildcards(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Walk(t *testing.T) {
+	testutil.Test_URLRouter_Walk(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_Dump(t *testing.T) {
+	testutil.Test_URLRouter_Dump(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_HostRouter_Lookup(t *testing.T) {
+	testutil.Test_HostRouter_Lookup(t, &DoubleArrayRouter{})
+}
+
+func Test_DoubleArray_LookupInto(t *testing.T) {
+	testutil.Test_URLRouter_LookupInto(t, &DoubleArrayRouter{})
+	testutil.Test_URLRouter_LookupInto_allocs(t, &DoubleArrayRouter{}, "/path/to/route", "/path/to/p1/sep/p2", "/a/to/b/p1/some/wildcard/params")
+}
+
 func Test_DoubleArray_Lookup_with_many_routes(t *testing.T) {
 	testutil.Test_URLRouter_Lookup_with_many_routes(t, &DoubleArrayRouter{})
 }
@@ -28,3 +82,133 @@ func Test_DoubleArray_Lookup_with_many_routes(t *testing.T) {
 func Test_DoubleArray_Build(t *testing.T) {
 	testutil.Test_URLRouter_Build(t, &DoubleArrayRouter{})
 }
+
+func Test_DoubleArray_Add_and_Remove(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	defer func() {
+		if t.Failed() {
+			t.Logf("seed: %d", seed)
+		}
+	}()
+	segments := []string{"a", "b", "ab", "ba", "abc", "a.b", ":p", ":p.html", ":i<int>", ":a([a-z]+).html", "*w"}
+	randomKey := func() string {
+		var buf bytes.Buffer
+		n := rnd.Intn(4)
+		for i := 0; i <= n; i++ {
+			seg := segments[rnd.Intn(len(segments))]
+			if seg[0] == ':' || seg[0] == '*' {
+				// every path parameter has the name that depends on the position to avoid a duplication.
+				seg = fmt.Sprintf("%c%s%d%s", seg[0], seg[1:2], i, seg[2:])
+			}
+			buf.WriteString("/" + seg)
+		}
+		return buf.String()
+	}
+	values := []string{"a", "b", "ab", "ba", "abc", "a.b", "xyz", "xyz.html", "1", "1.html"}
+	randomPath := func() string {
+		var buf bytes.Buffer
+		for i := rnd.Intn(5); i >= 0; i-- {
+			buf.WriteString("/" + values[rnd.Intn(len(values))])
+		}
+		return buf.String()
+	}
+
+	for n := 0; n < 50; n++ {
+		da := New()
+		if err := da.Build(nil); err != nil {
+			t.Fatal(err)
+		}
+		keys := make(map[string]int)
+		for i := 0; i < 100; i++ {
+			key := randomKey()
+			if _, exists := keys[key]; exists && rnd.Intn(3) == 0 {
+				if !da.Remove(key) {
+					t.Fatalf("Remove(%q) returns false, but the key exists", key)
+				}
+				delete(keys, key)
+			} else {
+				if err := da.Add(urlrouter.NewRecord(key, i)); err != nil {
+					t.Fatal(err)
+				}
+				keys[key] = i
+			}
+
+			var records []urlrouter.Record
+			for k, v := range keys {
+				records = append(records, urlrouter.NewRecord(k, v))
+			}
+			expectedDA := New()
+			if err := expectedDA.Build(records); err != nil {
+				t.Fatal(err)
+			}
+			for k := range keys {
+				actual, _ := da.Lookup(k)
+				expected, _ := expectedDA.Lookup(k)
+				if !reflect.DeepEqual(actual, expected) {
+					t.Fatalf("Lookup(%q) with %v: expect %v, but %v", k, records, expected, actual)
+				}
+			}
+			for j := 0; j < 20; j++ {
+				path := randomPath()
+				actual, actualParams := da.Lookup(path)
+				expected, expectedParams := expectedDA.Lookup(path)
+				if !reflect.DeepEqual(actual, expected) || !reflect.DeepEqual(actualParams, expectedParams) {
+					t.Fatalf("Lookup(%q) with %v: expect %v %v, but %v %v", path, records, expected, expectedParams, actual, actualParams)
+				}
+			}
+		}
+	}
+}
+
+func Test_DoubleArray_Add_and_Remove_with_optional_segments(t *testing.T) {
+	da := New()
+	if err := da.Build(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := da.Add(urlrouter.NewRecord("/archive(/:year(/:month))", "testroute0")); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/archive", "/archive/2014", "/archive/2014/01"} {
+		if actual, _ := da.Lookup(path); actual != "testroute0" {
+			t.Errorf("Lookup(%q) expects %v, but %v", path, "testroute0", actual)
+		}
+	}
+	if !da.Remove("/archive(/:year(/:month))") {
+		t.Errorf("Remove expects true, but false")
+	}
+	for _, path := range []string{"/archive", "/archive/2014", "/archive/2014/01"} {
+		if actual, _ := da.Lookup(path); actual != nil {
+			t.Errorf("Lookup(%q) expects nil, but %v", path, actual)
+		}
+	}
+	if err := da.Add(urlrouter.NewRecord("/archive(/:year", "testroute0")); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+}
+
+func Test_DoubleArray_Remove_missing(t *testing.T) {
+	da := New()
+	if err := da.Build([]urlrouter.Record{
+		urlrouter.NewRecord("/path/to/route", "testroute0"),
+		urlrouter.NewRecord("/user/:id", "testroute1"),
+		urlrouter.NewRecord("/static/*filepath", "testroute2"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"/path/to", "/path/to/route/a", "/user/:name", "/static/*path", "/static/*filepath/edit", "/missing"} {
+		if da.Remove(key) {
+			t.Errorf("Remove(%q) returns true, but the key doesn't exist", key)
+		}
+	}
+	for _, key := range []string{"/path/to/route", "/user/:id", "/static/*filepath"} {
+		if !da.Remove(key) {
+			t.Errorf("Remove(%q) returns false, but the key exists", key)
+		}
+	}
+	for _, path := range []string{"/path/to/route", "/user/1", "/static/file"} {
+		if actual, _ := da.Lookup(path); actual != nil {
+			t.Errorf("Lookup(%q) expect nil, but %v", path, actual)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package doublearray
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+// Width of a row of the occupancy map in the text format.
+const occupancyWidth = 64
+
+// Dump implements the urlrouter.Dumper.
+// It writes the static Double-Array and the Double-Array of the routes that have path parameters.
+// A Double-Array is written with its occupancy of BASE/CHECK and its used slots, and the nested Double-Arrays of
+// path parameters follow the slot that they belong to. In the occupancy map of the text format, '#' is a used slot
+// and '.' is an unused slot.
+func (da *DoubleArray) Dump(w io.Writer, format urlrouter.DumpFormat) error {
+	d := &dumper{w: w, syntax: da.opts.SyntaxOrDefault()}
+	switch format {
+	case urlrouter.DumpText:
+		d.text(da.static, "static", 0)
+		d.text(da.param, "param", 0)
+	case urlrouter.DumpDOT:
+		d.printf("digraph doublearray {\n\tnode [shape=box];\n")
+		d.dot(da.static, "static")
+		d.dot(da.param, "param")
+		d.printf("}\n")
+	default:
+		return fmt.Errorf("unsupported dump format %v", format)
+	}
+	return d.err
+}
+
+// dumper writes the Double-Arrays.
+type dumper struct {
+	w      io.Writer
+	syntax *urlrouter.Syntax
+
+	// Number of the Double-Arrays that have been written. It is used as an ID of the Double-Array in the DOT format.
+	n int
+
+	// The first error of writing.
+	err error
+}
+
+func (d *dumper) printf(format string, a ...interface{}) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, a...)
+	}
+}
+
+// text writes da and its nested Double-Arrays in the text format.
+func (d *dumper) text(da *doubleArray, label string, depth int) {
+	indent := strings.Repeat("  ", depth)
+	used := da.usedSlots()
+	d.printf("%s%s (%d slots, %d used, %.2f%%)\n", indent, label, len(da.bc), used, fillRate(used, len(da.bc)))
+	for i := 0; i < len(da.bc); i += occupancyWidth {
+		row := make([]byte, 0, occupancyWidth)
+		for j := i; j < i+occupancyWidth && j < len(da.bc); j++ {
+			if da.isUsed(j) {
+				row = append(row, '#')
+			} else {
+				row = append(row, '.')
+			}
+		}
+		d.printf("%s  %6d %s\n", indent, i, row)
+	}
+	for idx := range da.bc {
+		if !da.isUsed(idx) {
+			continue
+		}
+		d.printf("%s  %s\n", indent, da.slotLabel(idx))
+		nd := da.node[idx]
+		if nd == nil {
+			continue
+		}
+		for _, tree := range nd.paramTrees {
+			d.text(tree, tree.paramKey(d.syntax), depth+2)
+		}
+		if nd.wildcardTree != nil {
+			d.text(nd.wildcardTree, string(d.syntax.WildcardChar()), depth+2)
+		}
+	}
+}
+
+// dot writes da and its nested Double-Arrays as the clusters in the DOT format, and returns the ID of da.
+func (d *dumper) dot(da *doubleArray, label string) int {
+	id := d.n
+	d.n++
+	used := da.usedSlots()
+	label = fmt.Sprintf("%s (%d slots, %d used, %.2f%%)", label, len(da.bc), used, fillRate(used, len(da.bc)))
+	d.printf("\tsubgraph cluster_%d {\n\t\tlabel=%s;\n", id, strconv.Quote(label))
+	for idx := range da.bc {
+		if !da.isUsed(idx) {
+			continue
+		}
+		peripheries := 1
+		if nd := da.node[idx]; nd != nil && nd.data != nil {
+			peripheries = 2
+		}
+		d.printf("\t\tt%d_%d [label=%s, peripheries=%d];\n", id, idx, strconv.Quote(da.slotLabel(idx)), peripheries)
+		if check := da.bc[idx].check; check >= 0 {
+			d.printf("\t\tt%d_%d -> t%d_%d [label=%s];\n", id, check, id, idx, strconv.Quote(fmt.Sprintf("%q", byte(idx^da.bc[check].base))))
+		}
+	}
+	d.printf("\t}\n")
+	for idx := range da.bc {
+		nd := da.node[idx]
+		if !da.isUsed(idx) || nd == nil {
+			continue
+		}
+		for _, tree := range nd.paramTrees {
+			treeID := d.dot(tree, tree.paramKey(d.syntax))
+			d.printf("\tt%d_%d -> t%d_0 [label=%s, style=dashed];\n", id, idx, treeID, strconv.Quote(tree.paramKey(d.syntax)))
+		}
+		if nd.wildcardTree != nil {
+			wildcard := string(d.syntax.WildcardChar())
+			treeID := d.dot(nd.wildcardTree, wildcard)
+			d.printf("\tt%d_%d -> t%d_0 [label=%s, style=dashed];\n", id, idx, treeID, strconv.Quote(wildcard))
+		}
+	}
+	return id
+}
+
+// slotLabel returns the description of the slot of idx.
+func (da *doubleArray) slotLabel(idx int) string {
+	label := fmt.Sprintf("[%d] base=%d", idx, da.bc[idx].base)
+	if check := da.bc[idx].check; check >= 0 {
+		label = fmt.Sprintf("[%d] %q check=%d base=%d", idx, byte(idx^da.bc[check].base), check, da.bc[idx].base)
+	}
+	if nd := da.node[idx]; nd != nil && nd.data != nil {
+		label += leafLabel(nd)
+	}
+	return label
+}
+
+// leafLabel returns the description of the record of nd.
+func leafLabel(nd *node) string {
+	label := fmt.Sprintf(" => %v %v", nd.data, nd.paramNames)
+	if nd.priority != 0 {
+		label += fmt.Sprintf(" (priority %d)", nd.priority)
+	}
+	return label
+}
@@ -0,0 +1,143 @@
+package doublearray
+
+import (
+	"unsafe"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+// Stats represents the statistics of the memory usage of DoubleArray.
+// The counts are the totals of the static Double-Array, the Double-Array of path parameters and all of the nested
+// Double-Arrays of them.
+type Stats struct {
+	// Number of the slots of BASE/CHECK.
+	Slots int
+
+	// Number of the used slots of BASE/CHECK.
+	UsedSlots int
+
+	// Ratio of UsedSlots to Slots.
+	FillRatio float64
+
+	// Number of the nested Double-Arrays of path parameters.
+	ParamArrays int
+
+	// Number of the nested Double-Arrays of wildcard path parameters.
+	WildcardArrays int
+
+	// Number of the entries of the node maps.
+	Nodes int
+
+	// Estimated bytes of the routing table.
+	// It doesn't include the values of records, constraints and the overhead of maps.
+	Bytes int
+}
+
+// Stats returns the statistics of the memory usage of the routing table.
+func (da *DoubleArray) Stats() Stats {
+	var s Stats
+	da.static.stats(&s)
+	da.param.stats(&s)
+	if s.Slots > 0 {
+		s.FillRatio = float64(s.UsedSlots) / float64(s.Slots)
+	}
+	return s
+}
+
+// Compact rebuilds the routing table into the smallest layout.
+// The slots that were fragmented by Add and Remove are packed, and the arrays of BASE/CHECK are shrunk to the last
+// used slots. The arrays will be extended again when records are added after Compact.
+func (da *DoubleArray) Compact() error {
+	var records []urlrouter.Record
+	if err := da.walk(func(key string, nd *node) error {
+		records = append(records, urlrouter.Record{Key: key, Value: nd.data, Priority: nd.priority})
+		return nil
+	}); err != nil {
+		return err
+	}
+	// keys have already been prepared by the options, so they mustn't be prepared again by Build.
+	static, param := newDoubleArray(blockSize), newDoubleArray(blockSize)
+	s := da.opts.SyntaxOrDefault()
+	statics, params := makeRecords(s, records)
+	if err := static.build(s, statics, 0, 0); err != nil {
+		return err
+	}
+	if err := param.build(s, params, 0, 0); err != nil {
+		return err
+	}
+	static.shrink()
+	param.shrink()
+	da.static, da.param = static, param
+	return nil
+}
+
+// stats adds the statistics of da and its nested Double-Arrays to s.
+func (da *doubleArray) stats(s *Stats) {
+	s.Slots += len(da.bc)
+	s.UsedSlots += da.usedSlots()
+	s.Nodes += len(da.node)
+	s.Bytes += int(unsafe.Sizeof(*da)) + cap(da.bc)*int(unsafe.Sizeof(baseCheck{})) + cap(da.used)*8
+	for _, nd := range da.node {
+		s.Bytes += int(unsafe.Sizeof(0)+unsafe.Sizeof(nd)+unsafe.Sizeof(*nd)) + cap(nd.paramTrees)*int(unsafe.Sizeof(da))
+		s.Bytes += cap(nd.paramNames) * int(unsafe.Sizeof(""))
+		for _, name := range nd.paramNames {
+			s.Bytes += len(name)
+		}
+		for _, tree := range nd.paramTrees {
+			s.ParamArrays++
+			tree.stats(s)
+		}
+		if nd.wildcardTree != nil {
+			s.WildcardArrays++
+			nd.wildcardTree.stats(s)
+		}
+	}
+}
+
+// shrink shrinks the arrays of BASE/CHECK of da and its nested Double-Arrays to the last used slots.
+func (da *doubleArray) shrink() {
+	size := 0
+	for idx := range da.bc {
+		if !da.isUsed(idx) {
+			continue
+		}
+		// BASE of the node that has only path parameters points outside of the used slots.
+		if len(da.children(idx)) == 0 {
+			da.bc[idx].base = 0
+		}
+		size = idx + 1
+	}
+	da.bc = append([]baseCheck(nil), da.bc[:size]...)
+	da.used = nil
+	for _, nd := range da.node {
+		for _, tree := range nd.paramTrees {
+			tree.shrink()
+		}
+		if nd.wildcardTree != nil {
+			nd.wildcardTree.shrink()
+		}
+	}
+}
+
+// isUsed returns whether the slot of idx is used. The slot of the root is always used.
+func (da *doubleArray) isUsed(idx int) bool {
+	return idx == 0 || da.bc[idx].check >= 0
+}
+
+// usedSlots returns the number of the used slots of BASE/CHECK.
+func (da *doubleArray) usedSlots() (n int) {
+	for idx := range da.bc {
+		if da.isUsed(idx) {
+			n++
+		}
+	}
+	return n
+}
+
+// fillRate returns the percentage of used in size.
+func fillRate(used, size int) float64 {
+	if size == 0 {
+		return 0
+	}
+	return float64(used) * 100 / float64(size)
+}
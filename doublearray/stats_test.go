@@ -0,0 +1,117 @@
+package doublearray
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+func Test_DoubleArray_Stats(t *testing.T) {
+	da := New()
+	if err := da.Build([]urlrouter.Record{
+		urlrouter.NewRecord("/", "testroute0"),
+		urlrouter.NewRecord("/user/:id", "testroute1"),
+		urlrouter.NewRecord("/user/:id<int>/edit", "testroute2"),
+		urlrouter.NewRecord("/files/:name.:ext", "testroute3"),
+		urlrouter.NewRecord("/static/*filepath", "testroute4"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := da.Stats()
+	var actual, expected interface{} = []int{s.ParamArrays, s.WildcardArrays}, []int{4, 1}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	// static and param have a block, the nested Double-Arrays of path parameters have only the used slots of
+	// ":id" (1), ":id<int>" + "/edit" (6), ":name" + "." (2), ":ext" (1) and "*filepath" (1).
+	actual, expected = s.Slots, 2*blockSize+1+6+2+1+1
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	// static "/" (2), param "/user/", "/files/" and "/static/" (20), the nested roots (5), "/edit" (5) and "." (1).
+	actual, expected = s.UsedSlots, 2+20+5+5+1
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	actual, expected = s.FillRatio, float64(s.UsedSlots)/float64(s.Slots)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	if s.Nodes == 0 || s.Bytes < s.Slots {
+		t.Errorf("Expect Nodes and Bytes to be counted, but %+v", s)
+	}
+}
+
+func Test_DoubleArray_Compact(t *testing.T) {
+	da := New()
+	if err := da.Build(nil); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for i := 0; i < 200; i++ {
+		for _, key := range []string{"/path/%d/route", "/user/%d/:name", "/user/:id<int>/%d", "/static/%d/*filepath"} {
+			if err := da.Add(urlrouter.NewRecord(fmt.Sprintf(key, i), i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		paths = append(paths, fmt.Sprintf("/path/%d/route", i), fmt.Sprintf("/user/%d/alice", i), fmt.Sprintf("/user/7/%d", i), fmt.Sprintf("/static/%d/a/b", i))
+	}
+	for i := 0; i < 200; i += 2 {
+		da.Remove(fmt.Sprintf("/path/%d/route", i))
+		da.Remove(fmt.Sprintf("/user/%d/:name", i))
+	}
+	expected := make(map[string][]interface{})
+	for _, path := range paths {
+		data, params := da.Lookup(path)
+		expected[path] = []interface{}{data, params}
+	}
+	before := da.Stats()
+	if err := da.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	after := da.Stats()
+	if after.Slots >= before.Slots || after.FillRatio <= before.FillRatio {
+		t.Errorf("Expect the smaller layout, but before %+v, after %+v", before, after)
+	}
+	if after.Bytes >= before.Bytes {
+		t.Errorf("Expect the fewer bytes, but before %v, after %v", before.Bytes, after.Bytes)
+	}
+	check := func(da *DoubleArray) {
+		for _, path := range paths {
+			data, params := da.Lookup(path)
+			if actual := []interface{}{data, params}; !reflect.DeepEqual(actual, expected[path]) {
+				t.Errorf("Lookup(%q) expects %v, but %v", path, expected[path], actual)
+			}
+		}
+	}
+	check(da)
+
+	data, err := da.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := New()
+	decoded.Codec = da.Codec
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	check(decoded)
+
+	// the compacted table must be able to be updated.
+	for _, key := range []string{"/path/added", "/user/:id<int>/added", "/user/0/:name", "/zzz/:name/*filepath"} {
+		if err := da.Add(urlrouter.NewRecord(key, key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, expected := range map[string]interface{}{
+		"/path/added": "/path/added", "/user/8/added": "/user/:id<int>/added", "/user/0/bob": "/user/0/:name", "/zzz/a/b": "/zzz/:name/*filepath",
+	} {
+		if actual, _ := da.Lookup(path); actual != expected {
+			t.Errorf("Lookup(%q) expects %v, but %v", path, expected, actual)
+		}
+	}
+	expected["/user/0/alice"] = []interface{}{"/user/0/:name", []urlrouter.Param{{"name", "alice"}}}
+	check(da)
+}
@@ -0,0 +1,81 @@
+package urlrouter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError represents an error of parsing a key.
+type SyntaxError struct {
+	// Key that has the error. If the key has optional segments, it may be one of the keys expanded by ExpandOptional.
+	Key string
+
+	// Index of the record in the records, or -1 if the key isn't given by a record.
+	Index int
+
+	// Byte offset of the error in Key.
+	Offset int
+
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error implements the error.Error.
+func (e *SyntaxError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("syntax error: '%v' at offset %d: %v", e.Key, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("syntax error: record %d '%v' at offset %d: %v", e.Index, e.Key, e.Offset, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// syntaxError returns a new *SyntaxError of key that isn't given by a record yet.
+func syntaxError(key string, offset int, format string, a ...interface{}) *SyntaxError {
+	return &SyntaxError{Key: key, Index: -1, Offset: offset, Err: fmt.Errorf(format, a...)}
+}
+
+// DuplicateParamError represents that the name of a path parameter is used more than once in a key.
+type DuplicateParamError struct {
+	// Key that has the duplicated path parameters.
+	// If the key has optional segments, it may be one of the keys expanded by ExpandOptional.
+	Key string
+
+	// Index of the record in the records, or -1 if the key isn't given by a record.
+	Index int
+
+	// Name of the path parameter.
+	Name string
+
+	// Byte offset of the second path parameter of Name in Key.
+	Offset int
+}
+
+// Error implements the error.Error.
+func (e *DuplicateParamError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("duplicate path parameter `%v`: '%v' at offset %d", e.Name, e.Key, e.Offset)
+	}
+	return fmt.Sprintf("duplicate path parameter `%v`: record %d '%v' at offset %d", e.Name, e.Index, e.Key, e.Offset)
+}
+
+// Errors represents the errors of the records.
+// It supports errors.Is and errors.As for each of the errors.
+type Errors []error
+
+// Error implements the error.Error.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors.
+func (e Errors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,60 @@
+package urlrouter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_SyntaxError_Error(t *testing.T) {
+	for _, testcase := range []struct {
+		err      *SyntaxError
+		expected string
+	}{
+		{&SyntaxError{Key: "/user/:", Index: 3, Offset: 6, Err: errors.New("name of path parameter is empty")}, "syntax error: record 3 '/user/:' at offset 6: name of path parameter is empty"},
+		{&SyntaxError{Key: "/user/:", Index: -1, Offset: 6, Err: errors.New("name of path parameter is empty")}, "syntax error: '/user/:' at offset 6: name of path parameter is empty"},
+	} {
+		if actual := testcase.err.Error(); actual != testcase.expected {
+			t.Errorf("Expect %v, but %v", testcase.expected, actual)
+		}
+	}
+}
+
+func Test_DuplicateParamError_Error(t *testing.T) {
+	for _, testcase := range []struct {
+		err      *DuplicateParamError
+		expected string
+	}{
+		{&DuplicateParamError{Key: "/:id/:id", Index: 2, Name: "id", Offset: 5}, "duplicate path parameter `id`: record 2 '/:id/:id' at offset 5"},
+		{&DuplicateParamError{Key: "/:id/:id", Index: -1, Name: "id", Offset: 5}, "duplicate path parameter `id`: '/:id/:id' at offset 5"},
+	} {
+		if actual := testcase.err.Error(); actual != testcase.expected {
+			t.Errorf("Expect %v, but %v", testcase.expected, actual)
+		}
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	syntaxErr := &SyntaxError{Key: "/user/:", Index: 0, Offset: 6, Err: errors.New("name of path parameter is empty")}
+	dupErr := &DuplicateParamError{Key: "/:id/:id", Index: 1, Name: "id", Offset: 5}
+	var err error = Errors{syntaxErr, dupErr}
+	var actual, expected interface{} = err.Error(), syntaxErr.Error() + "\n" + dupErr.Error()
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	var dup *DuplicateParamError
+	if !errors.As(err, &dup) || dup != dupErr {
+		t.Errorf("Expect errors.As to find %v, but %v", dupErr, dup)
+	}
+
+	// Conflicts and ParamErrors are also multi-errors.
+	conflict := &ConflictError{Kind: DuplicateKey, Index: 1, OtherIndex: 0}
+	var ce *ConflictError
+	if !errors.As(error(Conflicts{conflict}), &ce) || ce != conflict {
+		t.Errorf("Expect errors.As to find %v, but %v", conflict, ce)
+	}
+	paramErr := &ParamError{Name: "id", Err: ErrParamNotFound}
+	if !errors.Is(error(ParamErrors{paramErr}), ErrParamNotFound) {
+		t.Errorf("Expect errors.Is to find %v, but not", ErrParamNotFound)
+	}
+}
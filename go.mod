@@ -0,0 +1,5 @@
+module github.com/naoina/kocha-urlrouter
+
+go 1.20
+
+require golang.org/x/text v0.22.0
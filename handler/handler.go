@@ -0,0 +1,170 @@
+// An adapter of URLRouter for net/http.
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+type contextKey struct{}
+
+// paramsKey is a key of path parameters in the context.
+var paramsKey = contextKey{}
+
+// Handler represents an http.Handler that dispatches a request to the handler that associated with the request path.
+// If the URLRouter is built with urlrouter.TrailingSlashRedirect, a request whose path matches a route only if a
+// trailing slash is added or removed is redirected to the fixed path with 301 Moved Permanently for GET and HEAD, or
+// 308 Permanent Redirect for other methods.
+// The values of records of URLRouter must be http.Handler or func(http.ResponseWriter, *http.Request), otherwise ServeHTTP panics.
+type Handler struct {
+	// NotFound is called when no route matches the request path.
+	// If it is nil, http.NotFound will be used.
+	NotFound http.Handler
+
+	// MethodNotAllowed is called when routes match the request path, but none of them is for the request method.
+	// The Allow header has been set before it is called. If it is nil, 405 Method Not Allowed will be responded.
+	// It is used only by the Handler that returned by NewMethod.
+	MethodNotAllowed http.Handler
+
+	// PanicHandler is called with the recovered value when a handler panics.
+	// If it is nil, a panic won't be recovered.
+	PanicHandler func(w http.ResponseWriter, r *http.Request, err interface{})
+
+	// UseRawPath specifies whether to use the escaped form of the request path (e.g. "/a%2Fb") to lookup.
+	// If it is false, r.URL.Path will be used.
+	// It must be true if the URLRouter is built with urlrouter.Options.Normalize, because the URLRouter decodes the
+	// path by itself, otherwise the path is decoded twice, e.g. "/%2541" is looked up as "/A" instead of "/%41".
+	UseRawPath bool
+
+	router       urlrouter.URLRouter
+	methodRouter *urlrouter.MethodRouter
+}
+
+// New returns a new Handler that uses the built router.
+func New(router urlrouter.URLRouter) *Handler {
+	return &Handler{router: router}
+}
+
+// NewMethod returns a new Handler that uses the built router to dispatch a request by the method and the path.
+// If routes match the request path, but none of them is for the request method, an OPTIONS request is responded
+// with 204 No Content and the Allow header, and other requests are responded by MethodNotAllowed.
+func NewMethod(router *urlrouter.MethodRouter) *Handler {
+	return &Handler{methodRouter: router}
+}
+
+// ServeHTTP implements the http.Handler.
+// Path parameters are stored in the context of the request, it can be retrieved by ParamsFromContext or Param.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.PanicHandler != nil {
+		defer func() {
+			if err := recover(); err != nil {
+				h.PanicHandler(w, r, err)
+			}
+		}()
+	}
+	path := r.URL.Path
+	if h.UseRawPath {
+		path = r.URL.EscapedPath()
+	}
+	var data interface{}
+	var params []urlrouter.Param
+	if h.methodRouter != nil {
+		var allow []string
+		if data, params, allow = h.methodRouter.Lookup(r.Method, path); data == nil && len(allow) > 0 {
+			h.methodNotAllowed(w, r, allow)
+			return
+		}
+	} else {
+		var fixed string
+		if data, params, fixed = urlrouter.LookupTrailingSlash(h.router, path); fixed != "" {
+			redirectTrailingSlash(w, r)
+			return
+		}
+	}
+	if data == nil {
+		if h.NotFound != nil {
+			h.NotFound.ServeHTTP(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+		return
+	}
+	if len(params) > 0 {
+		r = r.WithContext(NewContext(r.Context(), params))
+	}
+	switch handler := data.(type) {
+	case http.Handler:
+		handler.ServeHTTP(w, r)
+	case func(http.ResponseWriter, *http.Request):
+		handler(w, r)
+	default:
+		panic(fmt.Errorf("handler: value of the route for `%v` isn't an http.Handler: %T", path, data))
+	}
+}
+
+// methodNotAllowed responds to the request whose method isn't in allow.
+func (h *Handler) methodNotAllowed(w http.ResponseWriter, r *http.Request, allow []string) {
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	switch {
+	case strings.ToUpper(r.Method) == http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+	case h.MethodNotAllowed != nil:
+		h.MethodNotAllowed.ServeHTTP(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// redirectTrailingSlash redirects the request to the path that a trailing slash is added to or removed from the request path.
+func redirectTrailingSlash(w http.ResponseWriter, r *http.Request) {
+	u := *r.URL
+	if path := u.EscapedPath(); strings.HasSuffix(path, "/") {
+		u.RawPath = strings.TrimSuffix(path, "/")
+	} else {
+		u.RawPath = path + "/"
+	}
+	if strings.HasSuffix(u.Path, "/") {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	} else {
+		u.Path += "/"
+	}
+	// A location that begins with "//" or "/\" is treated as a network-path reference by browsers, e.g. "//evil.com/"
+	// redirects to another host, so the leading slashes are collapsed.
+	location := u.RequestURI()
+	if strings.HasPrefix(location, "//") || strings.HasPrefix(location, "/\\") {
+		location = "/" + strings.TrimLeft(location, "/\\")
+	}
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	http.Redirect(w, r, location, code)
+}
+
+// NewContext returns a new context that carries params.
+func NewContext(ctx context.Context, params []urlrouter.Param) context.Context {
+	return context.WithValue(ctx, paramsKey, params)
+}
+
+// ParamsFromContext returns the path parameters stored in ctx.
+// If ctx doesn't have path parameters, it returns nil.
+func ParamsFromContext(ctx context.Context) []urlrouter.Param {
+	params, _ := ctx.Value(paramsKey).([]urlrouter.Param)
+	return params
+}
+
+// Param returns a value of the path parameter with name in the context of r.
+// If the parameter doesn't exist, it returns an empty string.
+func Param(r *http.Request, name string) string {
+	return urlrouter.Params(ParamsFromContext(r.Context())).Get(name)
+}
+
+// Bind stores the path parameters in the context of r into dst by urlrouter.Bind.
+// If it returns urlrouter.ParamErrors, the request should be responded with 400 Bad Request.
+func Bind(r *http.Request, dst interface{}) error {
+	return urlrouter.Bind(ParamsFromContext(r.Context()), dst)
+}
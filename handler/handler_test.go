@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/tst"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	return newTestHandlerWithOptions(t, urlrouter.Options{})
+}
+
+func newTestHandlerWithOptions(t *testing.T, opts urlrouter.Options) *Handler {
+	router := tst.New()
+	router.SetOptions(opts)
+	if err := router.Build([]urlrouter.Record{
+		urlrouter.NewRecord("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "root")
+		})),
+		urlrouter.NewRecord("/user/:id", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "user %v %v", Param(r, "id"), ParamsFromContext(r.Context()))
+		}),
+		urlrouter.NewRecord("/files/:dir/*filepath", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "files %v %v", Param(r, "dir"), Param(r, "filepath"))
+		})),
+		urlrouter.NewRecord("/panic", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("panic!")
+		})),
+		urlrouter.NewRecord("/invalid", "invalid"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return New(router)
+}
+
+func Test_Handler_ServeHTTP(t *testing.T) {
+	h := newTestHandler(t)
+	for _, testcase := range []struct {
+		path   string
+		code   int
+		body   string
+		useRaw bool
+	}{
+		{"/", http.StatusOK, "root", false},
+		{"/user/777", http.StatusOK, "user 777 [{id 777}]", false},
+		{"/files/a/b/c.txt", http.StatusOK, "files a b/c.txt", false},
+		{"/files/a%2Fb/c.txt", http.StatusOK, "files a b/c.txt", false},
+		{"/files/a%2Fb/c.txt", http.StatusOK, "files a%2Fb c.txt", true},
+		{"/missing", http.StatusNotFound, "404 page not found\n", false},
+	} {
+		h.UseRawPath = testcase.useRaw
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", testcase.path, nil))
+		var actual, expected interface{} = w.Code, testcase.code
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%v: expect %v, but %v", testcase.path, expected, actual)
+		}
+		actual, expected = w.Body.String(), testcase.body
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%v: expect %q, but %q", testcase.path, expected, actual)
+		}
+	}
+}
+
+func Test_Handler_ServeHTTP_withNormalize(t *testing.T) {
+	router := urlrouter.WithOptions(&tst.TSTRouter{}, urlrouter.Options{Normalize: true}).New()
+	if err := router.Build([]urlrouter.Record{
+		urlrouter.NewRecord("/A", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "A")
+		})),
+		urlrouter.NewRecord("/%41", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "%41")
+		})),
+		urlrouter.NewRecord("/v/:value", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, Param(r, "value"))
+		})),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	h := New(router)
+	for _, testcase := range []struct {
+		path   string
+		useRaw bool
+		body   string
+	}{
+		{"/%2541", true, "%41"},
+		{"/%2541", false, "A"},
+		{"/%41", true, "A"},
+		{"/v/%2541", true, "%41"},
+		{"/v/caf%C3%A9", true, "caf\u00e9"},
+	} {
+		h.UseRawPath = testcase.useRaw
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", testcase.path, nil))
+		if actual, expected := w.Body.String(), testcase.body; actual != expected {
+			t.Errorf("%v with UseRawPath %v: expect %q, but %q", testcase.path, testcase.useRaw, expected, actual)
+		}
+	}
+}
+
+func Test_Handler_NotFound(t *testing.T) {
+	h := newTestHandler(t)
+	h.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	var actual, expected interface{} = w.Code, http.StatusTeapot
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_Handler_TrailingSlash(t *testing.T) {
+	h := newTestHandlerWithOptions
+	dirs := func(t *testing.T, opts urlrouter.Options) *Handler {
+		router := tst.New()
+		router.SetOptions(opts)
+		if err := router.Build([]urlrouter.Record{
+			urlrouter.NewRecord("/*dir/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return New(router)
+	}
+	for _, testcase := range []struct {
+		h        func(t *testing.T, opts urlrouter.Options) *Handler
+		policy   urlrouter.TrailingSlashPolicy
+		method   string
+		target   string
+		code     int
+		location string
+	}{
+		{h, urlrouter.TrailingSlashStrict, "GET", "/user/777/", http.StatusNotFound, ""},
+		{h, urlrouter.TrailingSlashRedirect, "GET", "/user/777", http.StatusOK, ""},
+		{h, urlrouter.TrailingSlashRedirect, "GET", "/user/777/", http.StatusMovedPermanently, "/user/777"},
+		{h, urlrouter.TrailingSlashRedirect, "HEAD", "/user/777/?q=1", http.StatusMovedPermanently, "/user/777?q=1"},
+		{h, urlrouter.TrailingSlashRedirect, "POST", "/user/777/", http.StatusPermanentRedirect, "/user/777"},
+		{h, urlrouter.TrailingSlashRedirect, "GET", "/user/a%2Fb/", http.StatusMovedPermanently, "/user/a%2Fb"},
+		{h, urlrouter.TrailingSlashRedirect, "GET", "/missing/", http.StatusNotFound, ""},
+		{h, urlrouter.TrailingSlashIgnore, "GET", "/user/777/", http.StatusOK, ""},
+		{dirs, urlrouter.TrailingSlashRedirect, "GET", "/a/b", http.StatusMovedPermanently, "/a/b/"},
+		{dirs, urlrouter.TrailingSlashRedirect, "GET", "http://example.com//evil.com", http.StatusMovedPermanently, "/evil.com/"},
+		{dirs, urlrouter.TrailingSlashRedirect, "GET", "http://example.com///evil.com?q=1", http.StatusMovedPermanently, "/evil.com/?q=1"},
+		{dirs, urlrouter.TrailingSlashRedirect, "GET", "http://example.com/%5Cevil.com", http.StatusMovedPermanently, "/%5Cevil.com/"},
+	} {
+		h := testcase.h(t, urlrouter.Options{TrailingSlash: testcase.policy})
+		h.UseRawPath = true
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(testcase.method, testcase.target, nil))
+		actual := []interface{}{w.Code, w.Header().Get("Location")}
+		expected := []interface{}{testcase.code, testcase.location}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%v %v with policy %v: expect %v, but %v", testcase.method, testcase.target, testcase.policy, expected, actual)
+		}
+	}
+}
+
+func Test_Handler_ServeHTTP_withMethodRouter(t *testing.T) {
+	router := urlrouter.NewMethodRouter(&tst.TSTRouter{})
+	if err := router.Build([]urlrouter.MethodRecord{
+		urlrouter.NewMethodRecord("GET", "/user/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "get user %v", Param(r, "id"))
+		})),
+		urlrouter.NewMethodRecord("DELETE", "/user/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "delete user %v", Param(r, "id"))
+		})),
+		urlrouter.NewMethodRecord("OPTIONS", "/cors", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "cors")
+		})),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewMethod(router)
+	for _, testcase := range []struct {
+		method string
+		path   string
+		code   int
+		allow  string
+		body   string
+	}{
+		{"GET", "/user/1", http.StatusOK, "", "get user 1"},
+		{"HEAD", "/user/1", http.StatusOK, "", "get user 1"},
+		{"DELETE", "/user/1", http.StatusOK, "", "delete user 1"},
+		{"POST", "/user/1", http.StatusMethodNotAllowed, "DELETE, GET, HEAD, OPTIONS", "Method Not Allowed\n"},
+		{"OPTIONS", "/user/1", http.StatusNoContent, "DELETE, GET, HEAD, OPTIONS", ""},
+		{"OPTIONS", "/cors", http.StatusOK, "", "cors"},
+		{"GET", "/missing", http.StatusNotFound, "", "404 page not found\n"},
+		{"OPTIONS", "/missing", http.StatusNotFound, "", "404 page not found\n"},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(testcase.method, testcase.path, nil))
+		actual := []interface{}{w.Code, w.Header().Get("Allow"), w.Body.String()}
+		expected := []interface{}{testcase.code, testcase.allow, testcase.body}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%v %v: expect %q, but %q", testcase.method, testcase.path, expected, actual)
+		}
+	}
+
+	h.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/user/1", nil))
+	var actual, expected interface{} = []interface{}{w.Code, w.Header().Get("Allow")}, []interface{}{http.StatusTeapot, "DELETE, GET, HEAD, OPTIONS"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_Handler_PanicHandler(t *testing.T) {
+	h := newTestHandler(t)
+	var recovered []interface{}
+	h.PanicHandler = func(w http.ResponseWriter, r *http.Request, err interface{}) {
+		recovered = append(recovered, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	for _, path := range []string{"/panic", "/invalid"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var actual, expected interface{} = w.Code, http.StatusInternalServerError
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%v: expect %v, but %v", path, expected, actual)
+		}
+	}
+	var actual, expected interface{} = len(recovered), 2
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	h.PanicHandler = nil
+	func() {
+		defer func() {
+			if err := recover(); err == nil {
+				t.Errorf("Expect panic, but not")
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+	}()
+}
+
+func Test_ParamsFromContext(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	var actual, expected interface{} = ParamsFromContext(r.Context()), []urlrouter.Param(nil)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	params := []urlrouter.Param{{Name: "id", Value: "1"}}
+	r = r.WithContext(NewContext(r.Context(), params))
+	actual, expected = ParamsFromContext(r.Context()), params
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	actual, expected = Param(r, "id"), "1"
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	actual, expected = Param(r, "missing"), ""
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_Bind(t *testing.T) {
+	var dst struct {
+		ID int `urlrouter:"id"`
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(NewContext(r.Context(), []urlrouter.Param{{Name: "id", Value: "1"}}))
+	if err := Bind(r, &dst); err != nil {
+		t.Fatal(err)
+	}
+	var actual, expected interface{} = dst.ID, 1
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	r = r.WithContext(NewContext(r.Context(), []urlrouter.Param{{Name: "id", Value: "alice"}}))
+	if _, ok := Bind(r, &dst).(urlrouter.ParamErrors); !ok {
+		t.Errorf("Expect urlrouter.ParamErrors, but not")
+	}
+}
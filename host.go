@@ -0,0 +1,93 @@
+package urlrouter
+
+import (
+	"strings"
+)
+
+// HostRecord represents a record data with a host for a HostRouter construction.
+type HostRecord struct {
+	// Host pattern such as "example.com" and ":tenant.example.com".
+	// Labels of the host can be path parameters, and their values are returned by HostRouter.Lookup along
+	// with path parameters. An empty Host matches any host.
+	Host string
+
+	Record
+}
+
+// NewHostRecord returns a new HostRecord.
+func NewHostRecord(host, key string, value interface{}) HostRecord {
+	return HostRecord{
+		Host:   host,
+		Record: NewRecord(key, value),
+	}
+}
+
+// HostRouter represents a router that routes by a host and a path.
+// It builds a URLRouter whose keys are the host patterns followed by the keys of the paths, so a host and a path are
+// matched together by the precedence. (see ComparePrecedence) The routes that have an empty host are built into
+// another URLRouter. The URLRouters are built by the Router.
+type HostRouter struct {
+	router  Router
+	hosts   URLRouter
+	anyHost URLRouter
+}
+
+// NewHostRouter returns a new HostRouter that builds URLRouters by router.
+// router can be any Router such as the one returned by WithOptions.
+func NewHostRouter(router Router) *HostRouter {
+	return &HostRouter{router: router}
+}
+
+// Build builds HostRouter from records.
+// A host of records is case-insensitive. A key of records should start with a separator such as "/" because it is
+// joined to the host, and the names of the path parameters must differ from the names of the host parameters.
+func (hr *HostRouter) Build(records []HostRecord) error {
+	var hostRecords, anyHostRecords []Record
+	for _, record := range records {
+		if record.Host == "" {
+			anyHostRecords = append(anyHostRecords, record.Record)
+			continue
+		}
+		record.Key = strings.ToLower(record.Host) + record.Key
+		hostRecords = append(hostRecords, record.Record)
+	}
+	hosts := hr.router.New()
+	if err := hosts.Build(hostRecords); err != nil {
+		return err
+	}
+	var anyHost URLRouter
+	if len(anyHostRecords) > 0 {
+		anyHost = hr.router.New()
+		if err := anyHost.Build(anyHostRecords); err != nil {
+			return err
+		}
+	}
+	hr.hosts, hr.anyHost = hosts, anyHost
+	return nil
+}
+
+// Lookup returns data and parameters that associated with host and path.
+// host may have a port such as "example.com:8080", the port will be ignored.
+// params is a slice of the Param that arranged in the order of the host parameters and the path parameters.
+// The routes that have a host pattern are preferred to the routes that have an empty host.
+// If failed to lookup, data will be nil.
+func (hr *HostRouter) Lookup(host, path string) (data interface{}, params []Param) {
+	if hr.hosts != nil {
+		if data, params := hr.hosts.Lookup(strings.ToLower(stripPort(host)) + path); data != nil {
+			return data, params
+		}
+	}
+	if hr.anyHost != nil {
+		return hr.anyHost.Lookup(path)
+	}
+	return nil, nil
+}
+
+// stripPort returns host without a port.
+func stripPort(host string) string {
+	i := strings.LastIndexByte(host, ':')
+	if i < 0 || strings.IndexByte(host[i:], ']') >= 0 {
+		return host
+	}
+	return host[:i]
+}
@@ -0,0 +1,28 @@
+package urlrouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_NewHostRouter(t *testing.T) {
+	router := &staticRouter{}
+	actual := NewHostRouter(router)
+	expected := &HostRouter{router: router}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_stripPort(t *testing.T) {
+	for host, expected := range map[string]string{
+		"example.com":      "example.com",
+		"example.com:8080": "example.com",
+		"[::1]":            "[::1]",
+		"[::1]:8080":       "[::1]",
+	} {
+		if actual := stripPort(host); actual != expected {
+			t.Errorf("%q expects %q, but %q", host, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,521 @@
+package urlrouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Route represents an entry of a routes file. It is the Value of the Records that are returned by LoadRoutes.
+type Route struct {
+	// HTTP method such as "GET" and "POST" in upper case.
+	Method string
+
+	// Name of the route such as "user.show", or empty if the entry has no name.
+	Name string
+
+	// HandlerName is the name of the handler in the registry.
+	HandlerName string
+
+	// Handler is the value of HandlerName in the registry.
+	Handler interface{}
+
+	// Metadata of the entry such as "auth=required", or nil if the entry has no metadata.
+	Metadata map[string]string
+
+	// Line of the entry in the routes file.
+	Line int
+}
+
+// LoadError represents an error of an entry of a routes file.
+type LoadError struct {
+	// Name of the routes file.
+	File string
+
+	// Line and column of the error. Both of them are 1-based, and the column is counted in bytes.
+	Line   int
+	Column int
+
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error implements the error.Error.
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("%v:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// LoadRoutes reads a routes file from r and returns the records of the entries.
+// It is the same as DefaultSyntax.LoadRoutes.
+func LoadRoutes(name string, r io.Reader, registry map[string]interface{}) ([]Record, error) {
+	return DefaultSyntax.LoadRoutes(name, r, registry)
+}
+
+// LoadRoutes reads a routes file from r and returns the records of the entries in the order of the file.
+// name is the name of the file that is used in the errors.
+//
+// The routes file is either in the line format or in JSON. The line format has an entry per line that consists of
+// a method, a key, a name of the handler and attributes separated by spaces. Empty lines and the rest of a line from
+// a field that starts with '#' are ignored.
+//
+//	# method  key              handler      attributes
+//	GET       /                home
+//	GET       /user/:id        users.Show   name=user.show id<int> auth=required
+//	POST      /user/:id/edit   users.Update id([0-9]+) priority=1 title="Edit user"
+//
+// An attribute is one of the following:
+//
+//	name<type> or name(regexp)  a constraint of the path parameter of name in the key (see NewConstraint)
+//	name=value                  Name of the route
+//	priority=N                  Priority of the record
+//	key=value                   metadata of the entry. value can be a quoted string of Go
+//
+// A routes file that starts with '[' is in JSON, an array of the objects that have the same fields as the line
+// format. "method", "key" and "handler" are required.
+//
+//	[
+//	  {"method": "GET", "key": "/", "handler": "home"},
+//	  {"method": "GET", "key": "/user/:id", "name": "user.show", "handler": "users.Show",
+//	   "constraints": {"id": "<int>"}, "metadata": {"auth": "required"}},
+//	  {"method": "POST", "key": "/user/:id/edit", "handler": "users.Update", "priority": 1}
+//	]
+//
+// The Key of a record is the key of the entry that the constraints are put into, and the Value is a *Route.
+// Handler of the Route is the value of the name of the handler in registry.
+// It returns Errors that holds a *LoadError for each of the entries that can't be loaded, or a *LoadError if the
+// file isn't valid JSON. The conflicts between the entries aren't checked, use Validate for that.
+func (s *Syntax) LoadRoutes(name string, r io.Reader, registry map[string]interface{}) ([]Record, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	var records []Record
+	var errs Errors
+	load := func(e *entry, err error) {
+		if err == nil && e != nil {
+			var record Record
+			if record, err = s.loadRoute(e, registry); err == nil {
+				records = append(records, record)
+			}
+		}
+		if err != nil {
+			err.(*LoadError).File = name
+			errs = append(errs, err)
+		}
+	}
+	if trimmed := bytes.TrimLeft(src, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		entries, err := parseJSON(src)
+		if err != nil {
+			err.(*LoadError).File = name
+			return nil, err
+		}
+		for _, e := range entries {
+			load(e, nil)
+		}
+	} else {
+		for i, line := range strings.Split(string(src), "\n") {
+			load(parseLine(line, i+1))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return records, nil
+}
+
+// entry represents an entry of a routes file before the handler is resolved.
+type entry struct {
+	method, key, name, handler field
+	priority                   *field
+	constraints, metadata      []attribute
+
+	// line of the entry.
+	line int
+}
+
+// attribute represents a constraint or metadata of an entry.
+type attribute struct {
+	name, value field
+}
+
+// loadRoute returns the record of e.
+func (s *Syntax) loadRoute(e *entry, registry map[string]interface{}) (Record, error) {
+	method, key := e.method, e.key
+	if method.text == "" {
+		return Record{}, method.errorf(0, "invalid method %q", method.text)
+	}
+	for i := 0; i < len(method.text); i++ {
+		if c := method.text[i]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return Record{}, method.errorf(i, "invalid method %q", method.text)
+		}
+	}
+	keys, err := s.ExpandOptional(key.text)
+	for i := 0; i < len(keys) && err == nil; i++ {
+		err = s.checkParams(keys[i], make(map[string]error))
+	}
+	if err != nil {
+		offset := 0
+		switch e := err.(type) {
+		case *SyntaxError:
+			if e.Key == key.text {
+				offset = e.Offset
+			}
+		case *DuplicateParamError:
+			if e.Key == key.text {
+				offset = e.Offset
+			}
+		}
+		return Record{}, &LoadError{Line: key.line, Column: key.column + offset, Err: err}
+	}
+	handler, exists := registry[e.handler.text]
+	if !exists {
+		return Record{}, e.handler.errorf(0, "handler `%v` isn't registered", e.handler.text)
+	}
+	route := &Route{
+		Method:      strings.ToUpper(method.text),
+		Name:        e.name.text,
+		HandlerName: e.handler.text,
+		Handler:     handler,
+		Line:        e.line,
+	}
+	record := Record{Key: key.text, Value: route}
+	for _, c := range e.constraints {
+		if _, err := NewConstraint(c.value.text); err != nil {
+			return Record{}, c.value.errorf(0, "%v", err)
+		}
+		k, err := s.putConstraint(record.Key, c.name.text, c.value.text)
+		if err != nil {
+			return Record{}, c.name.errorf(0, "%v", err)
+		}
+		record.Key = k
+	}
+	if e.priority != nil {
+		priority, err := strconv.Atoi(e.priority.text)
+		if err != nil {
+			return Record{}, e.priority.errorf(0, "invalid priority %q", e.priority.text)
+		}
+		record.Priority = priority
+	}
+	for _, m := range e.metadata {
+		if _, exists := route.Metadata[m.name.text]; exists {
+			return Record{}, m.name.errorf(0, "duplicate metadata `%v`", m.name.text)
+		}
+		if route.Metadata == nil {
+			route.Metadata = make(map[string]string)
+		}
+		route.Metadata[m.name.text] = m.value.text
+	}
+	return record, nil
+}
+
+// putConstraint returns the key that spec is put after the path parameter of name in key.
+func (s *Syntax) putConstraint(key, name, spec string) (string, error) {
+	for i := 0; i < len(key); i++ {
+		if !s.IsMetaChar(key[i]) {
+			continue
+		}
+		end := i + 1
+		for end < len(key) && isNameChar(key[end]) {
+			end++
+		}
+		if key[i+1:end] != name {
+			i = end - 1
+			continue
+		}
+		if key[i] == s.wildcardChar {
+			return "", fmt.Errorf("wildcard path parameter `%v` can't have a constraint", name)
+		}
+		if end < len(key) && (key[end] == '<' || key[end] == '(' && !(end+1 < len(key) && s.IsSeparator(key[end+1]))) {
+			return "", fmt.Errorf("path parameter `%v` already has a constraint", name)
+		}
+		return key[:end] + spec + key[end:], nil
+	}
+	return "", fmt.Errorf("path parameter `%v` isn't in the key", name)
+}
+
+// field represents a field of an entry of a routes file.
+type field struct {
+	text string
+
+	// 1-based line and column of the field.
+	line, column int
+}
+
+// errorf returns a new *LoadError at offset bytes from the start of f.
+func (f field) errorf(offset int, format string, a ...interface{}) *LoadError {
+	return &LoadError{Line: f.line, Column: f.column + offset, Err: fmt.Errorf(format, a...)}
+}
+
+// parseLine returns the entry of a line in the line format, or nil if the line has no entry.
+func parseLine(text string, line int) (*entry, error) {
+	fields, err := splitFields(text, line)
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+	if len(fields) < 3 {
+		last := fields[len(fields)-1]
+		return nil, last.errorf(len(last.text), "missing %v", [...]string{"", "key", "handler"}[len(fields)])
+	}
+	e := &entry{method: fields[0], key: fields[1], handler: fields[2], line: line}
+	for _, attr := range fields[3:] {
+		i := strings.IndexAny(attr.text, "=<(")
+		if i <= 0 {
+			return nil, attr.errorf(0, "invalid attribute %q", attr.text)
+		}
+		name := field{text: attr.text[:i], line: line, column: attr.column}
+		if attr.text[i] != '=' {
+			value := field{text: attr.text[i:], line: line, column: attr.column + i}
+			e.constraints = append(e.constraints, attribute{name: name, value: value})
+			continue
+		}
+		value := field{text: attr.text[i+1:], line: line, column: attr.column + i + 1}
+		if strings.HasPrefix(value.text, `"`) {
+			unquoted, err := strconv.Unquote(value.text)
+			if err != nil {
+				return nil, value.errorf(0, "invalid quoted string %v", value.text)
+			}
+			value.text = unquoted
+		}
+		switch name.text {
+		case "name":
+			if e.name.column > 0 {
+				return nil, name.errorf(0, "duplicate name")
+			}
+			e.name = value
+		case "priority":
+			if e.priority != nil {
+				return nil, name.errorf(0, "duplicate priority")
+			}
+			e.priority = &value
+		default:
+			e.metadata = append(e.metadata, attribute{name: name, value: value})
+		}
+	}
+	return e, nil
+}
+
+// splitFields returns the fields of text at line that are separated by spaces.
+// A field can have a quoted string that contains spaces, and a field that starts with '#' and the rest of text are
+// ignored.
+func splitFields(text string, line int) ([]field, error) {
+	var fields []field
+	for i := 0; i < len(text); {
+		if text[i] == ' ' || text[i] == '\t' || text[i] == '\r' {
+			i++
+			continue
+		}
+		if text[i] == '#' {
+			break
+		}
+		start := i
+		for i < len(text) && text[i] != ' ' && text[i] != '\t' && text[i] != '\r' {
+			if text[i] != '"' {
+				i++
+				continue
+			}
+			quote := i
+			for i++; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(text) {
+				return nil, field{line: line, column: quote + 1}.errorf(0, "quoted string not terminated")
+			}
+			i++
+		}
+		fields = append(fields, field{text: text[start:i], line: line, column: start + 1})
+	}
+	return fields, nil
+}
+
+// parseJSON returns the entries of a routes file in JSON.
+func parseJSON(src []byte) ([]*entry, error) {
+	p := &jsonParser{src: src, dec: json.NewDecoder(bytes.NewReader(src))}
+	p.dec.UseNumber()
+	if err := p.delim('['); err != nil {
+		return nil, err
+	}
+	var entries []*entry
+	for p.dec.More() {
+		e, err := p.entry()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := p.delim(']'); err != nil {
+		return nil, err
+	}
+	if rest := bytes.TrimLeft(src[p.dec.InputOffset():], " \t\r\n"); len(rest) > 0 {
+		return nil, p.at(len(src)-len(rest)).errorf(0, "unexpected data after the entries")
+	}
+	return entries, nil
+}
+
+// jsonParser parses a routes file in JSON token by token to know the positions of the values.
+type jsonParser struct {
+	src []byte
+	dec *json.Decoder
+}
+
+// entry parses an object of an entry.
+func (p *jsonParser) entry() (*entry, error) {
+	start := p.pos()
+	if err := p.delim('{'); err != nil {
+		return nil, err
+	}
+	e := &entry{line: start.line}
+	seen := make(map[string]bool)
+	for p.dec.More() {
+		tok, pos, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		name := tok.(string)
+		if seen[name] {
+			return nil, pos.errorf(1, "duplicate field %q", name)
+		}
+		seen[name] = true
+		switch name {
+		case "method":
+			e.method, err = p.str(name)
+		case "key":
+			e.key, err = p.str(name)
+		case "name":
+			e.name, err = p.str(name)
+		case "handler":
+			e.handler, err = p.str(name)
+		case "priority":
+			var priority field
+			priority, err = p.number(name)
+			e.priority = &priority
+		case "constraints":
+			e.constraints, err = p.object(name)
+		case "metadata":
+			e.metadata, err = p.object(name)
+		default:
+			return nil, pos.errorf(1, "unknown field %q", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := p.delim('}'); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"method", "key", "handler"} {
+		if !seen[name] {
+			return nil, start.errorf(0, "missing %v", name)
+		}
+	}
+	return e, nil
+}
+
+// object parses an object of the strings in the field of name.
+func (p *jsonParser) object(name string) ([]attribute, error) {
+	if err := p.delim('{'); err != nil {
+		return nil, err
+	}
+	var attrs []attribute
+	for p.dec.More() {
+		tok, pos, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.str(name + "." + tok.(string))
+		if err != nil {
+			return nil, err
+		}
+		pos.text, pos.column = tok.(string), pos.column+1
+		attrs = append(attrs, attribute{name: pos, value: value})
+	}
+	return attrs, p.delim('}')
+}
+
+// str parses a string in the field of name.
+func (p *jsonParser) str(name string) (field, error) {
+	tok, pos, err := p.token()
+	if err != nil {
+		return field{}, err
+	}
+	s, ok := tok.(string)
+	if !ok {
+		return field{}, pos.errorf(0, "%v must be a string", name)
+	}
+	pos.text, pos.column = s, pos.column+1
+	return pos, nil
+}
+
+// number parses a number in the field of name.
+func (p *jsonParser) number(name string) (field, error) {
+	tok, pos, err := p.token()
+	if err != nil {
+		return field{}, err
+	}
+	n, ok := tok.(json.Number)
+	if !ok {
+		return field{}, pos.errorf(0, "%v must be a number", name)
+	}
+	pos.text = string(n)
+	return pos, nil
+}
+
+// delim parses a delimiter of c.
+func (p *jsonParser) delim(c byte) error {
+	tok, pos, err := p.token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim(c) {
+		return pos.errorf(0, "expected '%c'", c)
+	}
+	return nil
+}
+
+// token returns the next token and its position.
+func (p *jsonParser) token() (json.Token, field, error) {
+	pos := p.pos()
+	tok, err := p.dec.Token()
+	if err != nil {
+		if e, ok := err.(*json.SyntaxError); ok {
+			// Offset is after the invalid character, or at the end of the source if the source ends unexpectedly.
+			if pos = p.at(int(e.Offset)); int(e.Offset) < len(p.src) {
+				pos.column--
+			}
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, pos, pos.errorf(0, "%v", err)
+	}
+	return tok, pos, nil
+}
+
+// pos returns the position of the next token.
+func (p *jsonParser) pos() field {
+	offset := int(p.dec.InputOffset())
+	for offset < len(p.src) && strings.IndexByte(" \t\r\n,:", p.src[offset]) >= 0 {
+		offset++
+	}
+	return p.at(offset)
+}
+
+// at returns the position of offset in the source.
+func (p *jsonParser) at(offset int) field {
+	if offset > len(p.src) {
+		offset = len(p.src)
+	}
+	return field{
+		line:   1 + bytes.Count(p.src[:offset], []byte("\n")),
+		column: offset - bytes.LastIndexByte(p.src[:offset], '\n'),
+	}
+}
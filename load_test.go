@@ -0,0 +1,182 @@
+package urlrouter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func Test_LoadRoutes(t *testing.T) {
+	registry := map[string]interface{}{
+		"home":         "homehandler",
+		"users.Show":   "showhandler",
+		"users.Update": "updatehandler",
+		"archive":      "archivehandler",
+	}
+	src := `# method  key              handler      attributes
+
+GET       /                home
+get       /user/:id        users.Show   name=user.show id<int> auth=required  # comment
+	POST /user/:id/edit users.Update id([0-9]+) priority=1 title="Edit \"user\"" auth=
+GET       /archive(/:year)  archive     year<int>
+`
+	records, err := LoadRoutes("routes", strings.NewReader(src), registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual interface{} = records
+	var expected interface{} = []Record{
+		{Key: "/", Value: &Route{Method: "GET", HandlerName: "home", Handler: "homehandler", Line: 3}},
+		{Key: "/user/:id<int>", Value: &Route{Method: "GET", Name: "user.show", HandlerName: "users.Show", Handler: "showhandler", Metadata: map[string]string{"auth": "required"}, Line: 4}},
+		{Key: "/user/:id([0-9]+)/edit", Value: &Route{Method: "POST", HandlerName: "users.Update", Handler: "updatehandler", Metadata: map[string]string{"title": `Edit "user"`, "auth": ""}, Line: 5}, Priority: 1},
+		{Key: "/archive(/:year<int>)", Value: &Route{Method: "GET", HandlerName: "archive", Handler: "archivehandler", Line: 6}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	// the same routes in JSON.
+	src = `
+[
+  {"method": "GET", "key": "/", "handler": "home"},
+  {"method": "get", "key": "/user/:id", "name": "user.show", "handler": "users.Show",
+   "constraints": {"id": "<int>"}, "metadata": {"auth": "required"}},
+  {
+    "method": "POST",
+    "key": "/user/:id/edit",
+    "handler": "users.Update",
+    "constraints": {"id": "([0-9]+)"},
+    "priority": 1,
+    "metadata": {"title": "Edit \"user\"", "auth": ""}
+  },
+  {"method": "GET", "key": "/archive(/:year)", "handler": "archive", "constraints": {"year": "<int>"}}
+]
+`
+	records, err = LoadRoutes("routes.json", strings.NewReader(src), registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual = records
+	expected = []Record{
+		{Key: "/", Value: &Route{Method: "GET", HandlerName: "home", Handler: "homehandler", Line: 3}},
+		{Key: "/user/:id<int>", Value: &Route{Method: "GET", Name: "user.show", HandlerName: "users.Show", Handler: "showhandler", Metadata: map[string]string{"auth": "required"}, Line: 4}},
+		{Key: "/user/:id([0-9]+)/edit", Value: &Route{Method: "POST", HandlerName: "users.Update", Handler: "updatehandler", Metadata: map[string]string{"title": `Edit "user"`, "auth": ""}, Line: 6}, Priority: 1},
+		{Key: "/archive(/:year<int>)", Value: &Route{Method: "GET", HandlerName: "archive", Handler: "archivehandler", Line: 14}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	// a line isn't limited in length.
+	records, err = LoadRoutes("routes", strings.NewReader("GET /"+strings.Repeat("a", 1<<17)+" home\n"), registry)
+	actual, expected = []interface{}{len(records), err}, []interface{}{1, nil}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	records, err = LoadRoutes("routes", strings.NewReader("\n# comment\n"), registry)
+	actual, expected = []interface{}{records, err}, []interface{}{[]Record(nil), nil}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_LoadRoutes_withErrors(t *testing.T) {
+	registry := map[string]interface{}{"user": "userhandler"}
+	for _, testcase := range []struct {
+		line     string
+		expected string
+	}{
+		{"GET", "routes:2:4: missing key"},
+		{"GET /user/:id", "routes:2:14: missing handler"},
+		{"G-T /user/:id user", `routes:2:2: invalid method "G-T"`},
+		{"GET /user/:-x user", "routes:2:11: syntax error: '/user/:-x' at offset 6: "},
+		{"GET /:id/:id user", "routes:2:10: duplicate path parameter `id`: '/:id/:id' at offset 5"},
+		{"GET /user(/:id user", "routes:2:10: syntax error: '/user(/:id' at offset 5: "},
+		{"GET /user/:id missing", "routes:2:15: handler `missing` isn't registered"},
+		{"GET /user/:id user =x", `routes:2:20: invalid attribute "=x"`},
+		{"GET /user/:id user auth", `routes:2:20: invalid attribute "auth"`},
+		{"GET /user/:id user id<unknown>", "routes:2:22: unknown constraint type `<unknown>`"},
+		{"GET /user/:id user id([0-9]+", "routes:2:22: invalid constraint `([0-9]+`"},
+		{"GET /user/:name user id<int>", "routes:2:22: path parameter `id` isn't in the key"},
+		{"GET /user/:id<int> user id<int>", "routes:2:25: path parameter `id` already has a constraint"},
+		{"GET /static/*path user path<int>", "routes:2:24: wildcard path parameter `path` can't have a constraint"},
+		{"GET /user/:id user priority=high", `routes:2:29: invalid priority "high"`},
+		{`GET /user/:id user title="a\q"`, `routes:2:26: invalid quoted string "a\q"`},
+		{`GET /user/:id user title="a b`, "routes:2:26: quoted string not terminated"},
+		{"GET /user/:id user a=1 a=2", "routes:2:24: duplicate metadata `a`"},
+		{"GET /user/:id user name=a name=b", "routes:2:27: duplicate name"},
+	} {
+		_, err := LoadRoutes("routes", strings.NewReader("GET / user\n"+testcase.line+"\n"), registry)
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), testcase.expected) {
+			t.Errorf("%q expects %q, but %v", testcase.line, testcase.expected, err)
+		}
+	}
+
+	// all the errors of the entries are returned.
+	_, err := LoadRoutes("routes", strings.NewReader("GET /a missing\nGET /:-x user\nGET /b user\nGET /c\n"), registry)
+	var lines []int
+	var loadErr *LoadError
+	for _, err := range err.(Errors) {
+		if errors.As(err, &loadErr) {
+			lines = append(lines, loadErr.Line)
+		}
+	}
+	var actual interface{} = lines
+	var expected interface{} = []int{1, 2, 4}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("Expect errors.As to find *SyntaxError in %v", err)
+	}
+
+	// an error of the reader is wrapped with the name of the file.
+	readErr := errors.New("read error")
+	_, err = LoadRoutes("routes", iotest.ErrReader(readErr), registry)
+	if !errors.Is(err, readErr) || !strings.HasPrefix(err.Error(), "routes: ") {
+		t.Errorf("Expect %v wrapped with the name of the file, but %v", readErr, err)
+	}
+}
+
+func Test_LoadRoutes_withJSONErrors(t *testing.T) {
+	registry := map[string]interface{}{"user": "userhandler"}
+	for _, testcase := range []struct {
+		src      string
+		expected string
+	}{
+		{`[{"method": "GET", "key": "/user/:id"}]`, "routes:1:2: missing handler"},
+		{`[{"method": "GET", "key": "/user/:id", "handler": "missing"}]`, "routes:1:52: handler `missing` isn't registered"},
+		{`[{"method": "G-T", "key": "/user/:id", "handler": "user"}]`, `routes:1:15: invalid method "G-T"`},
+		{`[{"method": "GET", "key": "/user/:-x", "handler": "user"}]`, "routes:1:34: syntax error: '/user/:-x' at offset 6: "},
+		{`[{"method": "GET", "key": "/user/:id", "handler": "user", "constraints": {"id": "<unknown>"}}]`, "routes:1:82: unknown constraint type `<unknown>`"},
+		{`[{"method": "GET", "key": "/user/:id", "handler": "user", "constraints": {"name": "<int>"}}]`, "routes:1:76: path parameter `name` isn't in the key"},
+		{`[{"method": "GET", "key": "/user/:id", "handler": "user", "priority": 1.5}]`, `routes:1:71: invalid priority "1.5"`},
+		{`[{"method": "GET", "key": "/user/:id", "handler": "user", "priority": "1"}]`, "routes:1:71: priority must be a number"},
+		{`[{"method": "GET", "key": "/user/:id", "handler": "user", "metadata": {"auth": 1}}]`, "routes:1:80: metadata.auth must be a string"},
+		{`[{"method": "GET", "key": "/user/:id", "handler": "user", "auth": "required"}]`, `routes:1:60: unknown field "auth"`},
+		{`[{"method": "GET", "method": "GET"}]`, `routes:1:21: duplicate field "method"`},
+		{"[\n  {\"method\": \"GET\",,}\n]", "routes:2:20: invalid character ',' looking for beginning of value"},
+		{`[{"method": "GET"`, "routes:1:18: unexpected end of JSON input"},
+		{`[] []`, "routes:1:4: unexpected data after the entries"},
+		{`[1]`, "routes:1:2: expected '{'"},
+	} {
+		_, err := LoadRoutes("routes", strings.NewReader(testcase.src), registry)
+		if err == nil || !strings.HasPrefix(err.Error(), testcase.expected) {
+			t.Errorf("%q expects %q, but %v", testcase.src, testcase.expected, err)
+		}
+	}
+}
+
+func Test_LoadError_Error(t *testing.T) {
+	err := &LoadError{File: "routes", Line: 3, Column: 5, Err: errors.New("handler `user` isn't registered")}
+	var actual interface{} = err.Error()
+	var expected interface{} = "routes:3:5: handler `user` isn't registered"
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
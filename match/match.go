@@ -0,0 +1,89 @@
+// Package match provides Matcher that is shared by the implementations of urlrouter.URLRouter, including the ones
+// that are generated by urlrouter-gen.
+package match
+
+import (
+	"strings"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+// Matcher chooses the node that has the highest priority from the nodes that matched a path.
+// It is used by the implementations of urlrouter.URLRouter that try the nodes of the routing table in the order of
+// the precedence. N is the type of the nodes.
+type Matcher[N any] struct {
+	// maximum priority of the records. The lookup will be stopped when the node that has it is matched.
+	maxPriority int
+
+	caseInsensitive  bool
+	shortestWildcard bool
+	syntax           *urlrouter.Syntax
+
+	// the matched node that has the highest priority and the values of its path parameters.
+	node     N
+	params   []urlrouter.Param
+	priority int
+	found    bool
+}
+
+// New returns a new Matcher for the routing table that is built with opts.
+func New[N any](maxPriority int, opts urlrouter.Options) Matcher[N] {
+	return Matcher[N]{
+		maxPriority:      maxPriority,
+		caseInsensitive:  opts.CaseInsensitive,
+		shortestWildcard: opts.ShortestWildcard,
+		syntax:           opts.SyntaxOrDefault(),
+	}
+}
+
+// CaseInsensitive returns whether upper-case ASCII letters of the path are folded.
+func (m *Matcher[N]) CaseInsensitive() bool {
+	return m.caseInsensitive
+}
+
+// ShortestWildcard returns whether the shortest values of wildcard path parameters are tried first.
+func (m *Matcher[N]) ShortestWildcard() bool {
+	return m.shortestWildcard
+}
+
+// Syntax returns the Syntax of the keys and the path.
+func (m *Matcher[N]) Syntax() *urlrouter.Syntax {
+	return m.syntax
+}
+
+// Result returns the matched node that has the highest priority and the values of its path parameters.
+// found is false if no node matched.
+func (m *Matcher[N]) Result() (node N, params []urlrouter.Param, found bool) {
+	return m.node, m.params, m.found
+}
+
+// Match passes the matched node that has priority to m. It reports whether the lookup should be stopped.
+// params is copied unless the lookup is stopped, so the caller can reuse it.
+func (m *Matcher[N]) Match(nd N, priority int, params []urlrouter.Param) bool {
+	if m.found && priority <= m.priority {
+		return false
+	}
+	m.node, m.priority, m.found = nd, priority, true
+	if priority >= m.maxPriority {
+		m.params = params
+		return true
+	}
+	m.params = append(m.params[:0:0], params...)
+	return false
+}
+
+// HasPrefix returns whether path begins with prefix. prefix must be folded if m is case-insensitive.
+func (m *Matcher[N]) HasPrefix(path, prefix string) bool {
+	if !m.caseInsensitive {
+		return strings.HasPrefix(path, prefix)
+	}
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := 0; i < len(prefix); i++ {
+		if urlrouter.FoldByte(path[i]) != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+func Test_Matcher_Match(t *testing.T) {
+	m := New[string](2, urlrouter.Options{})
+	params := []urlrouter.Param{{Value: "a"}}
+	for _, testcase := range []struct {
+		node     string
+		priority int
+		expected bool
+	}{
+		{"testroute0", 0, false},
+		{"testroute1", 0, false},
+		{"testroute2", 1, false},
+		{"testroute3", 2, true},
+	} {
+		if actual := m.Match(testcase.node, testcase.priority, params); actual != testcase.expected {
+			t.Errorf("Match(%q, %v) => Expect %v, but %v", testcase.node, testcase.priority, testcase.expected, actual)
+		}
+		params[0].Value += "a"
+	}
+	node, matched, found := m.Result()
+	var actual, expected interface{} = []interface{}{found, node, matched}, []interface{}{true, "testroute3", params}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	m = New[string](2, urlrouter.Options{})
+	m.Match("testroute0", 1, params)
+	params[0].Value = "b"
+	node, matched, found = m.Result()
+	actual, expected = []interface{}{found, node, matched}, []interface{}{true, "testroute0", []urlrouter.Param{{Value: "aaaaa"}}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_Matcher_HasPrefix(t *testing.T) {
+	for _, testcase := range []struct {
+		caseInsensitive bool
+		path, prefix    string
+		expected        bool
+	}{
+		{false, "/Users/a", "/Users", true},
+		{false, "/users/a", "/Users", false},
+		{true, "/USERS/a", "/users", true},
+		{true, "/us", "/users", false},
+		{true, "/posts", "/users", false},
+	} {
+		m := New[string](0, urlrouter.Options{CaseInsensitive: testcase.caseInsensitive})
+		if actual := m.HasPrefix(testcase.path, testcase.prefix); actual != testcase.expected {
+			t.Errorf("HasPrefix(%q, %q) with CaseInsensitive %v => Expect %v, but %v", testcase.path, testcase.prefix, testcase.caseInsensitive, testcase.expected, actual)
+		}
+	}
+}
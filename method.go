@@ -0,0 +1,126 @@
+package urlrouter
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	methodGet     = "GET"
+	methodHead    = "HEAD"
+	methodOptions = "OPTIONS"
+)
+
+// MethodRecord represents a record data with an HTTP method for a MethodRouter construction.
+type MethodRecord struct {
+	// HTTP method such as "GET" and "POST".
+	Method string
+
+	Record
+}
+
+// NewMethodRecord returns a new MethodRecord.
+func NewMethodRecord(method, key string, value interface{}) MethodRecord {
+	return MethodRecord{
+		Method: method,
+		Record: NewRecord(key, value),
+	}
+}
+
+// MethodRouter represents a router that routes by an HTTP method and a path.
+// It builds a URLRouter for each method by the Router.
+type MethodRouter struct {
+	router  Router
+	routers map[string]URLRouter
+	methods []string
+}
+
+// NewMethodRouter returns a new MethodRouter that builds URLRouters by router.
+// router can be any Router such as the one returned by WithOptions.
+func NewMethodRouter(router Router) *MethodRouter {
+	return &MethodRouter{router: router}
+}
+
+// Build builds MethodRouter from records.
+// A method of records is case-insensitive.
+func (mr *MethodRouter) Build(records []MethodRecord) error {
+	recordsByMethod := make(map[string][]Record)
+	for _, record := range records {
+		method := strings.ToUpper(record.Method)
+		recordsByMethod[method] = append(recordsByMethod[method], record.Record)
+	}
+	routers := make(map[string]URLRouter, len(recordsByMethod))
+	methods := make([]string, 0, len(recordsByMethod))
+	for method, records := range recordsByMethod {
+		router := mr.router.New()
+		if err := router.Build(records); err != nil {
+			return err
+		}
+		routers[method] = router
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	mr.routers, mr.methods = routers, methods
+	return nil
+}
+
+// Lookup returns data and path parameters that associated with method and path.
+// The HEAD method falls back to the GET method if the routes for HEAD don't match path.
+// If failed to lookup, data will be nil, and allow will be the methods that are allowed for path.
+// In that case, the caller should respond with 405 Method Not Allowed and the Allow header if allow isn't empty,
+// or 404 Not Found if allow is empty. Note that if method is OPTIONS and the routes for OPTIONS don't match path,
+// the caller can respond to the OPTIONS request with allow.
+// The Handler that returned by handler.NewMethod responds so.
+func (mr *MethodRouter) Lookup(method, path string) (data interface{}, params []Param, allow []string) {
+	method = strings.ToUpper(method)
+	if data, params := mr.lookup(method, path); data != nil {
+		return data, params, nil
+	}
+	if method == methodHead {
+		if data, params := mr.lookup(methodGet, path); data != nil {
+			return data, params, nil
+		}
+	}
+	return nil, nil, mr.Allow(path)
+}
+
+// Allow returns the methods that are allowed for path in ascending order.
+// HEAD is allowed if GET is allowed, and OPTIONS is always allowed if any method is allowed.
+func (mr *MethodRouter) Allow(path string) (allow []string) {
+	for _, method := range mr.methods {
+		if data, _ := mr.routers[method].Lookup(path); data != nil {
+			allow = append(allow, method)
+		}
+	}
+	if len(allow) == 0 {
+		return nil
+	}
+	for _, method := range []string{methodHead, methodOptions} {
+		if method == methodHead && !containsString(allow, methodGet) {
+			continue
+		}
+		if !containsString(allow, method) {
+			allow = append(allow, method)
+		}
+	}
+	sort.Strings(allow)
+	return allow
+}
+
+func (mr *MethodRouter) lookup(method, path string) (data interface{}, params []Param) {
+	router, exists := mr.routers[method]
+	if !exists {
+		return nil, nil
+	}
+	return router.Lookup(path)
+}
+
+// containsString returns whether ss contains s.
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package urlrouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+type staticRouter struct{}
+
+func (r *staticRouter) New() URLRouter {
+	return &staticURLRouter{}
+}
+
+// staticURLRouter is a URLRouter for testing that only matches exactly.
+type staticURLRouter struct {
+	routes map[string]interface{}
+}
+
+func (r *staticURLRouter) Lookup(path string) (data interface{}, params []Param) {
+	return r.routes[path], nil
+}
+
+func (r *staticURLRouter) Build(records []Record) error {
+	r.routes = make(map[string]interface{})
+	for _, record := range records {
+		r.routes[record.Key] = record.Value
+	}
+	return nil
+}
+
+func Test_NewMethodRouter(t *testing.T) {
+	router := &staticRouter{}
+	actual := NewMethodRouter(router)
+	expected := &MethodRouter{router: router}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_MethodRouter_Lookup(t *testing.T) {
+	mr := NewMethodRouter(&staticRouter{})
+	if err := mr.Build([]MethodRecord{
+		NewMethodRecord("GET", "/", "getroot"),
+		NewMethodRecord("get", "/user", "getuser"),
+		NewMethodRecord("POST", "/user", "postuser"),
+		NewMethodRecord("DELETE", "/user", "deleteuser"),
+		NewMethodRecord("POST", "/login", "postlogin"),
+		NewMethodRecord("HEAD", "/head", "headhead"),
+		NewMethodRecord("OPTIONS", "/options", "optionsoptions"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		method string
+		path   string
+		data   interface{}
+		allow  []string
+	}{
+		{"GET", "/", "getroot", nil},
+		{"GET", "/user", "getuser", nil},
+		{"post", "/user", "postuser", nil},
+		{"DELETE", "/user", "deleteuser", nil},
+		{"HEAD", "/user", "getuser", nil},
+		{"HEAD", "/head", "headhead", nil},
+		{"OPTIONS", "/options", "optionsoptions", nil},
+		{"PUT", "/user", nil, []string{"DELETE", "GET", "HEAD", "OPTIONS", "POST"}},
+		{"OPTIONS", "/user", nil, []string{"DELETE", "GET", "HEAD", "OPTIONS", "POST"}},
+		{"GET", "/login", nil, []string{"OPTIONS", "POST"}},
+		{"HEAD", "/login", nil, []string{"OPTIONS", "POST"}},
+		{"GET", "/head", nil, []string{"HEAD", "OPTIONS"}},
+		{"GET", "/options", nil, []string{"OPTIONS"}},
+		{"GET", "/missing", nil, nil},
+		{"OPTIONS", "/missing", nil, nil},
+	} {
+		data, _, allow := mr.Lookup(testcase.method, testcase.path)
+		var actual, expected interface{} = data, testcase.data
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%v %v: expect %v, but %v", testcase.method, testcase.path, expected, actual)
+		}
+		actual, expected = allow, testcase.allow
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%v %v: expect %v, but %v", testcase.method, testcase.path, expected, actual)
+		}
+	}
+}
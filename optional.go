@@ -0,0 +1,227 @@
+package urlrouter
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// OptionalCharacter is a suffix of a path parameter that makes the segment of the parameter optional.
+	// e.g. "/users/:id?" matches both "/users/1" and "/users".
+	OptionalCharacter = '?'
+
+	// maxVariants is the maximum number of keys that are expanded from a key.
+	maxVariants = 256
+)
+
+// ExpandOptional returns the keys that are expanded from key that has optional segments.
+// An optional segment is a part of key that enclosed in parentheses and starts with a separator such as
+// "/archive(/:year(/:month))", or a path parameter that suffixed by '?' such as "/users/:id?".
+// The separator that precedes a path parameter suffixed by '?' is a part of the optional segment.
+// The key that has all optional segments comes first and the key that has none of them comes last.
+// An anonymous path parameter, that is the meta character followed by a separator or the end of key such as "/users/:"
+// or a wildcard path parameter "**" that occupies a segment, is named by its index of the path parameters in key,
+// e.g. "/**/index.html" is expanded to "/*0/index.html", and the value is the path parameter named "0".
+// key is returned as is if it has neither optional segments nor anonymous path parameters.
+// It returns a *SyntaxError if key can't be parsed.
+//
+//	ExpandOptional("/archive(/:year(/:month))") // => ["/archive/:year/:month", "/archive/:year", "/archive"]
+//	ExpandOptional("/users/:id?/edit")          // => ["/users/:id/edit", "/users/edit"]
+//	ExpandOptional("/docs(/**)/index.html")     // => ["/docs/*0/index.html", "/docs/index.html"]
+//
+// It is the same as DefaultSyntax.ExpandOptional.
+func ExpandOptional(key string) ([]string, error) {
+	return DefaultSyntax.ExpandOptional(key)
+}
+
+// ExpandOptional returns the keys that are expanded from key that has optional segments. See ExpandOptional.
+func (s *Syntax) ExpandOptional(key string) ([]string, error) {
+	key = s.nameAnonymous(key)
+	if strings.IndexAny(key, "(?<") < 0 {
+		// fast path. key has neither optional segments nor constraints that can be broken.
+		return []string{key}, nil
+	}
+	variants, _, err := s.expandOptional(key, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	keys := variants[:0]
+	dups := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		if !dups[v] {
+			keys = append(keys, v)
+			dups[v] = true
+		}
+	}
+	return keys, nil
+}
+
+// ExpandRecords returns the records that the keys are expanded by ExpandOptional.
+// The expanded records have the same Value and Priority as the original.
+// It also parses the path parameters of the expanded keys, and returns Errors that holds a *SyntaxError or a
+// *DuplicateParamError for each of the records that can't be built. Index of the errors is an index of records.
+// It is the same as DefaultSyntax.ExpandRecords.
+func ExpandRecords(records []Record) ([]Record, error) {
+	return DefaultSyntax.ExpandRecords(records)
+}
+
+// ExpandRecords returns the records that the keys are expanded by ExpandOptional of s. See ExpandRecords.
+func (s *Syntax) ExpandRecords(records []Record) ([]Record, error) {
+	expanded := make([]Record, 0, len(records))
+	var errs Errors
+	constraints := make(map[string]error)
+	for i, record := range records {
+		keys, err := s.ExpandOptional(record.Key)
+		for j := 0; j < len(keys) && err == nil; j++ {
+			err = s.checkParams(keys[j], constraints)
+		}
+		if err != nil {
+			errs = append(errs, withIndex(err, i))
+			continue
+		}
+		for _, key := range keys {
+			record.Key = key
+			expanded = append(expanded, record)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return expanded, nil
+}
+
+// checkParams returns an error if the path parameters of key can't be parsed or are duplicated.
+// constraints is a cache of the results of NewConstraint.
+func (s *Syntax) checkParams(key string, constraints map[string]error) error {
+	var names []string
+	for i := 0; i < len(key); i++ {
+		if !s.IsMetaChar(key[i]) {
+			continue
+		}
+		name, constraint, next, err := s.ParseParam(key, i)
+		if err != nil {
+			return err
+		}
+		if containsString(names, name) {
+			return &DuplicateParamError{Key: key, Index: -1, Name: name, Offset: i}
+		}
+		names = append(names, name)
+		if constraint != "" {
+			err, checked := constraints[constraint]
+			if !checked {
+				_, err = NewConstraint(constraint)
+				constraints[constraint] = err
+			}
+			if err != nil {
+				return &SyntaxError{Key: key, Index: -1, Offset: i + 1 + len(name), Err: err}
+			}
+		}
+		i = next - 1
+	}
+	return nil
+}
+
+// withIndex sets index of the record to err, and returns it.
+func withIndex(err error, index int) error {
+	switch e := err.(type) {
+	case *SyntaxError:
+		e.Index = index
+	case *DuplicateParamError:
+		e.Index = index
+	}
+	return err
+}
+
+// expandOptional expands key from key[start] to the end of key, or to the end of the optional segment if inGroup is true.
+// It returns the expanded keys and an index of the next of the end.
+func (s *Syntax) expandOptional(key string, start int, inGroup bool) (variants []string, end int, err error) {
+	variants = []string{""}
+	for i := start; i < len(key); {
+		switch c := key[i]; {
+		case c == ')' && inGroup:
+			return variants, i + 1, nil
+		case c == '(' && i+1 < len(key) && s.IsSeparator(key[i+1]):
+			group, next, err := s.expandOptional(key, i+1, true)
+			if err != nil {
+				return nil, -1, err
+			}
+			if variants, err = combineVariants(variants, append(group, "")); err != nil {
+				return nil, -1, &SyntaxError{Key: key, Index: -1, Offset: i, Err: err}
+			}
+			i = next
+		case s.IsMetaChar(c):
+			next, err := s.optionalParamEnd(key, i, inGroup)
+			if err != nil {
+				return nil, -1, err
+			}
+			param := key[i:next]
+			if next < len(key) && key[next] == OptionalCharacter {
+				if i == 0 || !s.IsSeparator(key[i-1]) {
+					return nil, -1, syntaxError(key, i, "optional path parameter must follow a separator")
+				}
+				if after := next + 1; after < len(key) && !s.IsSeparator(key[after]) && !(inGroup && key[after] == ')') {
+					return nil, -1, syntaxError(key, after, "optional path parameter must be followed by a separator")
+				}
+				if variants, err = combineVariants(variants, []string{param, ""}); err != nil {
+					return nil, -1, &SyntaxError{Key: key, Index: -1, Offset: i, Err: err}
+				}
+				// the separator that precedes the parameter is a part of the optional segment.
+				for j := len(variants) / 2; j < len(variants); j++ {
+					variants[j] = variants[j][:len(variants[j])-1]
+				}
+				next++
+			} else {
+				for j := range variants {
+					variants[j] += param
+				}
+			}
+			i = next
+		default:
+			next := i + 1
+			for next < len(key) && key[next] != '(' && key[next] != ')' && !s.IsMetaChar(key[next]) {
+				next++
+			}
+			for j := range variants {
+				variants[j] += key[i:next]
+			}
+			i = next
+		}
+	}
+	if inGroup {
+		// the optional segment begins with '(' that precedes start.
+		return nil, -1, syntaxError(key, start-1, "optional segment isn't closed by ')'")
+	}
+	return variants, len(key), nil
+}
+
+// optionalParamEnd returns an index of the next of the end of the path parameter that starts at key[start].
+// Unlike ParseParam, a parameter ends before ')' if it is in an optional segment, and before '?' that makes it optional.
+func (s *Syntax) optionalParamEnd(key string, start int, inGroup bool) (int, error) {
+	i := start + 1
+	for i < len(key) && isNameChar(key[i]) {
+		i++
+	}
+	if key[start] == s.wildcardChar {
+		// a constraint of wildcard path parameter will be reported by ParseParam.
+		return i, nil
+	}
+	if i < len(key) && (key[i] == '<' || key[i] == '(' && !(i+1 < len(key) && s.IsSeparator(key[i+1]))) {
+		return constraintEnd(key, i)
+	}
+	return i, nil
+}
+
+// combineVariants returns the keys that each of tails are appended to each of heads.
+// The keys are arranged in the order of tails.
+func combineVariants(heads, tails []string) ([]string, error) {
+	if len(heads)*len(tails) > maxVariants {
+		return nil, fmt.Errorf("too many optional segments")
+	}
+	variants := make([]string, 0, len(heads)*len(tails))
+	for _, tail := range tails {
+		for _, head := range heads {
+			variants = append(variants, head+tail)
+		}
+	}
+	return variants, nil
+}
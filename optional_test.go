@@ -0,0 +1,111 @@
+package urlrouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ExpandOptional(t *testing.T) {
+	for _, testcase := range []struct {
+		key      string
+		expected []string
+	}{
+		{"/", []string{"/"}},
+		{"/path/to/route", []string{"/path/to/route"}},
+		{"/user/:id<int>", []string{"/user/:id<int>"}},
+		{"/file/:name([a-z]+).:ext", []string{"/file/:name([a-z]+).:ext"}},
+		{"/archive(/:year(/:month))", []string{"/archive/:year/:month", "/archive/:year", "/archive"}},
+		{"/archive(/:year<int>(/:month<int>))", []string{"/archive/:year<int>/:month<int>", "/archive/:year<int>", "/archive"}},
+		{"/archive(/:year([0-9]{4}))", []string{"/archive/:year([0-9]{4})", "/archive"}},
+		{"/users/:id?", []string{"/users/:id", "/users"}},
+		{"/users/:id<int>?", []string{"/users/:id<int>", "/users"}},
+		{"/users/:id?/edit", []string{"/users/:id/edit", "/users/edit"}},
+		{"/file/:name.:ext?", []string{"/file/:name.:ext", "/file/:name"}},
+		{"/a/:x?/:y?", []string{"/a/:x/:y", "/a/:y", "/a/:x", "/a"}},
+		{"/static(/*filepath)", []string{"/static/*filepath", "/static"}},
+		{"/static/*filepath?", []string{"/static/*filepath", "/static"}},
+		{"/a(/b)(/b)", []string{"/a/b/b", "/a/b", "/a"}},
+		{"/a(b)", []string{"/a(b)"}},
+		{"/**/index.html", []string{"/*0/index.html"}},
+		{"/:dir/**", []string{"/:dir/*1"}},
+		{"/:id([*]+)/**", []string{"/:id([*]+)/*1"}},
+		{"/docs(/**)/index.html", []string{"/docs/*0/index.html", "/docs/index.html"}},
+		{"/**?/index.html", []string{"/*0/index.html", "/index.html"}},
+		{"/**/**", []string{"/*0/*1"}},
+		{"/user/:", []string{"/user/:0"}},
+		{"/:/:id/*", []string{"/:0/:id/*2"}},
+		{"/a/:?", []string{"/a/:0", "/a"}},
+		{"/a(/:)", []string{"/a/:0", "/a"}},
+	} {
+		actual, err := ExpandOptional(testcase.key)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", testcase.key, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, testcase.expected) {
+			t.Errorf("%q expects %q, but %q", testcase.key, testcase.expected, actual)
+		}
+	}
+
+	for _, key := range []string{
+		"/archive(/:year", "/archive(/:year(/:month)", "/users:id?", "/users/:id?edit", "/users/:id<int?",
+		"/:a?/:b?/:c?/:d?/:e?/:f?/:g?/:h?/:i?",
+	} {
+		if actual, err := ExpandOptional(key); err == nil {
+			t.Errorf("%q expects error, but returned %q", key, actual)
+		}
+	}
+}
+
+func Test_ExpandRecords(t *testing.T) {
+	actual, err := ExpandRecords([]Record{
+		NewRecord("/", "testroute0"),
+		{Key: "/users/:id?", Value: "testroute1", Priority: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Record{
+		NewRecord("/", "testroute0"),
+		{Key: "/users/:id", Value: "testroute1", Priority: 1},
+		{Key: "/users", Value: "testroute1", Priority: 1},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	_, err = ExpandRecords([]Record{
+		NewRecord("/", "testroute0"),
+		NewRecord("/archive(/:year", "testroute1"),
+		NewRecord("/user/:id/:id", "testroute2"),
+		NewRecord("/user/:-x", "testroute3"),
+		NewRecord("/user/:id<unknown>", "testroute4"),
+		NewRecord("/users/:name?/:name", "testroute5"),
+		NewRecord("/user/:id", "testroute6"),
+	})
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Expect Errors, but %#v", err)
+	}
+	var actualErrs []interface{}
+	for _, err := range errs {
+		switch e := err.(type) {
+		case *SyntaxError:
+			actualErrs = append(actualErrs, []interface{}{e.Key, e.Index, e.Offset, e.Err.Error()})
+		case *DuplicateParamError:
+			actualErrs = append(actualErrs, []interface{}{e.Key, e.Index, e.Offset, e.Name})
+		default:
+			t.Errorf("Expect *SyntaxError or *DuplicateParamError, but %#v", err)
+		}
+	}
+	expectedErrs := []interface{}{
+		[]interface{}{"/archive(/:year", 1, 8, "optional segment isn't closed by ')'"},
+		[]interface{}{"/user/:id/:id", 2, 10, "id"},
+		[]interface{}{"/user/:-x", 3, 6, "name of path parameter is empty"},
+		[]interface{}{"/user/:id<unknown>", 4, 9, "unknown constraint type `<unknown>`"},
+		[]interface{}{"/users/:name/:name", 5, 13, "name"},
+	}
+	if !reflect.DeepEqual(actualErrs, expectedErrs) {
+		t.Errorf("Expect %v, but %v", expectedErrs, actualErrs)
+	}
+}
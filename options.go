@@ -0,0 +1,196 @@
+package urlrouter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Options represents the build options of URLRouter.
+type Options struct {
+	// CaseInsensitive specifies whether the static parts of keys match paths case-insensitively.
+	// Only ASCII letters are folded in all implementations.
+	// Values of path parameters keep the original case of the path.
+	// Keys that differ only in case can't be built together. (see CheckDuplicateKeys)
+	CaseInsensitive bool
+
+	// Normalize specifies whether paths are percent-decoded and normalized into NFC before matching.
+	// Keys are normalized into NFC when building, they must be written in the decoded form.
+	// Paths must be passed in the escaped form, otherwise they are decoded twice, e.g. "/%2541" becomes "/A".
+	// Values of path parameters are the decoded and normalized ones.
+	Normalize bool
+
+	// ShortestWildcard specifies whether a wildcard path parameter that is followed by a literal matches the
+	// shortest value instead of the longest one.
+	ShortestWildcard bool
+
+	// Syntax specifies the separators and the meta characters of keys and paths.
+	// If it is nil, DefaultSyntax will be used.
+	Syntax *Syntax
+
+	// TrailingSlash specifies how to treat a path that matches a route only if a trailing slash is added or removed.
+	// Lookup matches such a path unless it is TrailingSlashStrict, and LookupTrailingSlash returns the fixed path.
+	TrailingSlash TrailingSlashPolicy
+}
+
+// SyntaxOrDefault returns opts.Syntax, or DefaultSyntax if it is nil.
+func (opts Options) SyntaxOrDefault() *Syntax {
+	if opts.Syntax == nil {
+		return DefaultSyntax
+	}
+	return opts.Syntax
+}
+
+// Configurable is an interface that may be implemented by a URLRouter to support Options.
+type Configurable interface {
+	// SetOptions sets the build options. It must be called before Build.
+	SetOptions(opts Options)
+}
+
+// WithOptions returns a Router whose URLRouters are configured with opts.
+// The URLRouters must implement Configurable, otherwise they fail to build.
+func WithOptions(router Router, opts Options) Router {
+	return &optionsRouter{router: router, opts: opts}
+}
+
+type optionsRouter struct {
+	router Router
+	opts   Options
+}
+
+// New returns a new URLRouter that configured with the options.
+func (r *optionsRouter) New() URLRouter {
+	ur := r.router.New()
+	c, ok := ur.(Configurable)
+	if !ok {
+		return &unconfigurableURLRouter{ur}
+	}
+	c.SetOptions(r.opts)
+	return ur
+}
+
+type unconfigurableURLRouter struct {
+	URLRouter
+}
+
+// Build returns an error always because the URLRouter doesn't support Options.
+func (r *unconfigurableURLRouter) Build(records []Record) error {
+	return fmt.Errorf("%T doesn't support build options", r.URLRouter)
+}
+
+// FoldByte returns a lower-case letter of c if c is an upper-case ASCII letter, otherwise returns c.
+func FoldByte(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// FoldStatic returns a key that upper-case ASCII letters of the static parts of key are converted to lower-case.
+// Names and constraints of path parameters are kept as it is.
+// It is the same as DefaultSyntax.FoldStatic.
+func FoldStatic(key string) string {
+	return DefaultSyntax.FoldStatic(key)
+}
+
+// FoldStatic returns a key that upper-case ASCII letters of the static parts of key are converted to lower-case.
+// See FoldStatic.
+func (s *Syntax) FoldStatic(key string) string {
+	buf := []byte(key)
+	for i := 0; i < len(buf); i++ {
+		if !s.IsMetaChar(buf[i]) {
+			buf[i] = FoldByte(buf[i])
+			continue
+		}
+		_, _, next, err := s.ParseParam(key, i)
+		if err != nil {
+			// the error will be reported by a URLRouter.
+			break
+		}
+		i = next - 1
+	}
+	return string(buf)
+}
+
+// NormalizePath returns a path that percent-decoded and normalized into NFC.
+// If path has an invalid percent-encoding, it won't be decoded.
+func NormalizePath(path string) string {
+	if strings.IndexByte(path, '%') >= 0 {
+		if decoded, err := url.PathUnescape(path); err == nil {
+			path = decoded
+		}
+	}
+	return norm.NFC.String(path)
+}
+
+// NormalizeRecords returns the records that keys are prepared in accordance with opts.
+func NormalizeRecords(records []Record, opts Options) []Record {
+	if !opts.CaseInsensitive && !opts.Normalize {
+		return records
+	}
+	normalized := make([]Record, len(records))
+	for i, record := range records {
+		record.Key = normalizeKey(record.Key, opts)
+		normalized[i] = record
+	}
+	return normalized
+}
+
+// normalizeKey returns a key that prepared in accordance with opts.
+func normalizeKey(key string, opts Options) string {
+	if opts.Normalize {
+		key = norm.NFC.String(key)
+	}
+	if opts.CaseInsensitive {
+		key = opts.SyntaxOrDefault().FoldStatic(key)
+	}
+	return key
+}
+
+// Validate is the same as Validate of the Syntax of opts, but the keys of records are prepared by NormalizeRecords
+// before the validation, so the keys that differ only in case are reported as DuplicateKey if CaseInsensitive is
+// true. Record and Other of ConflictError are the prepared records.
+func (opts Options) Validate(records []Record) error {
+	return opts.SyntaxOrDefault().Validate(NormalizeRecords(records, opts))
+}
+
+// CheckDuplicateKeys returns a *ConflictError of DuplicateKey if the keys of two records differ, but they become
+// the same when they are prepared in accordance with opts, e.g. "/Ab" and "/aB" if CaseInsensitive is true.
+// The implementations of URLRouter don't agree on which of such records wins, so Build of them returns the error.
+// The keys that have optional segments are checked after expanding by ExpandOptional as well as Validate.
+// The keys that are exactly the same aren't reported, use Validate for that.
+func (opts Options) CheckDuplicateKeys(records []Record) error {
+	if !opts.CaseInsensitive && !opts.Normalize {
+		return nil
+	}
+	type origin struct {
+		index int
+		key   string
+	}
+	s := opts.SyntaxOrDefault()
+	origins := make(map[string]origin)
+	for i, record := range records {
+		keys, err := s.ExpandOptional(record.Key)
+		if err != nil {
+			// the error will be reported by a URLRouter.
+			continue
+		}
+		for _, key := range keys {
+			normalized := normalizeKey(key, opts)
+			o, exists := origins[normalized]
+			if !exists {
+				origins[normalized] = origin{index: i, key: key}
+				continue
+			}
+			if o.key != key {
+				other := records[o.index]
+				other.Key = o.key
+				record.Key = key
+				return &ConflictError{Kind: DuplicateKey, Index: i, Record: record, OtherIndex: o.index, Other: other}
+			}
+		}
+	}
+	return nil
+}
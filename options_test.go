@@ -0,0 +1,168 @@
+package urlrouter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type configurableURLRouter struct {
+	staticURLRouter
+	opts Options
+}
+
+func (r *configurableURLRouter) SetOptions(opts Options) {
+	r.opts = opts
+}
+
+type configurableRouter struct{}
+
+func (r *configurableRouter) New() URLRouter {
+	return &configurableURLRouter{}
+}
+
+func Test_WithOptions(t *testing.T) {
+	opts := Options{CaseInsensitive: true, Normalize: true}
+	r := WithOptions(&configurableRouter{}, opts).New()
+	var actual, expected interface{} = r.(*configurableURLRouter).opts, opts
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	if err := r.Build([]Record{NewRecord("/a", "testroute0")}); err != nil {
+		t.Fatal(err)
+	}
+
+	r = WithOptions(&staticRouter{}, opts).New()
+	if err := r.Build([]Record{NewRecord("/a", "testroute0")}); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+}
+
+func Test_FoldByte(t *testing.T) {
+	for _, testcase := range []struct {
+		c, expected byte
+	}{
+		{'A', 'a'}, {'Z', 'z'}, {'a', 'a'}, {'z', 'z'}, {'0', '0'}, {'/', '/'}, {'@', '@'}, {'[', '['}, {0xc3, 0xc3},
+	} {
+		if actual := FoldByte(testcase.c); actual != testcase.expected {
+			t.Errorf("FoldByte(%q) expects %q, but %q", testcase.c, testcase.expected, actual)
+		}
+	}
+}
+
+func Test_FoldStatic(t *testing.T) {
+	for _, testcase := range []struct {
+		key, expected string
+	}{
+		{"/Path/To/Route", "/path/to/route"},
+		{"/User/:ID", "/user/:ID"},
+		{"/User/:ID<int>/Edit", "/user/:ID<int>/edit"},
+		{"/File/:Name([A-Z]+).PNG", "/file/:Name([A-Z]+).png"},
+		{"/Static/*FilePath", "/static/*FilePath"},
+		{"/\u00dcnicode", "/\u00dcnicode"},
+	} {
+		if actual := FoldStatic(testcase.key); actual != testcase.expected {
+			t.Errorf("FoldStatic(%q) expects %q, but %q", testcase.key, testcase.expected, actual)
+		}
+	}
+}
+
+func Test_NormalizePath(t *testing.T) {
+	for _, testcase := range []struct {
+		path, expected string
+	}{
+		{"/path/to/route", "/path/to/route"},
+		{"/caf%C3%A9", "/caf\u00e9"},
+		{"/caf\u00e9", "/caf\u00e9"},
+		{"/cafe\u0301", "/caf\u00e9"},
+		{"/cafe%CC%81", "/caf\u00e9"},
+		{"/a%20b", "/a b"},
+		{"/a%2Fb", "/a/b"},
+		{"/a%zzb", "/a%zzb"},
+	} {
+		if actual := NormalizePath(testcase.path); actual != testcase.expected {
+			t.Errorf("NormalizePath(%q) expects %q, but %q", testcase.path, testcase.expected, actual)
+		}
+	}
+}
+
+func Test_NormalizeRecords(t *testing.T) {
+	records := []Record{
+		NewRecord("/Cafe\u0301/:Name", "testroute0"),
+		{Key: "/Path", Value: "testroute1", Priority: 1},
+	}
+	for _, testcase := range []struct {
+		opts     Options
+		expected []Record
+	}{
+		{Options{}, records},
+		{Options{CaseInsensitive: true}, []Record{
+			NewRecord("/cafe\u0301/:Name", "testroute0"),
+			{Key: "/path", Value: "testroute1", Priority: 1},
+		}},
+		{Options{Normalize: true}, []Record{
+			NewRecord("/Caf\u00e9/:Name", "testroute0"),
+			{Key: "/Path", Value: "testroute1", Priority: 1},
+		}},
+		{Options{CaseInsensitive: true, Normalize: true}, []Record{
+			NewRecord("/caf\u00e9/:Name", "testroute0"),
+			{Key: "/path", Value: "testroute1", Priority: 1},
+		}},
+	} {
+		actual := NormalizeRecords(records, testcase.opts)
+		if !reflect.DeepEqual(actual, testcase.expected) {
+			t.Errorf("opts = %+v; expect %v, but %v", testcase.opts, testcase.expected, actual)
+		}
+	}
+	if records[0].Key != "/Cafe\u0301/:Name" {
+		t.Errorf("records must not be modified, but %q", records[0].Key)
+	}
+}
+
+func Test_Options_Validate(t *testing.T) {
+	records := []Record{
+		NewRecord("/Ab", "testroute0"),
+		NewRecord("/..:z<int>/", "testroute1"),
+		NewRecord("/aBA/", "testroute2"),
+		NewRecord("/aB", "testroute3"),
+	}
+	if err := (Options{}).Validate(records); err != nil {
+		t.Errorf("Expect nil, but %v", err)
+	}
+	var actual, expected interface{} = (Options{CaseInsensitive: true}).Validate(records), Conflicts{
+		{Kind: DuplicateKey, Index: 3, Record: NewRecord("/ab", "testroute3"), OtherIndex: 0, Other: NewRecord("/ab", "testroute0")},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_Options_CheckDuplicateKeys(t *testing.T) {
+	for _, testcase := range []struct {
+		opts     Options
+		keys     []string
+		expected error
+	}{
+		{Options{}, []string{"/Ab", "/aB"}, nil},
+		{Options{CaseInsensitive: true}, []string{"/Ab", "/aBA/", "/Ab"}, nil},
+		{Options{CaseInsensitive: true}, []string{"/Ab", "/..:z<int>/", "/aBA/", "/aB"}, &ConflictError{
+			Kind: DuplicateKey, Index: 3, Record: NewRecord("/aB", "testroute3"), OtherIndex: 0, Other: NewRecord("/Ab", "testroute0"),
+		}},
+		{Options{CaseInsensitive: true}, []string{"/user/:Id", "/USER(/:Id)"}, &ConflictError{
+			Kind: DuplicateKey, Index: 1, Record: NewRecord("/USER/:Id", "testroute1"), OtherIndex: 0, Other: NewRecord("/user/:Id", "testroute0"),
+		}},
+		{Options{CaseInsensitive: true}, []string{"/user/:Id", "/user/:id"}, nil},
+		{Options{Normalize: true}, []string{"/caf\u00e9", "/cafe\u0301"}, &ConflictError{
+			Kind: DuplicateKey, Index: 1, Record: NewRecord("/cafe\u0301", "testroute1"), OtherIndex: 0, Other: NewRecord("/caf\u00e9", "testroute0"),
+		}},
+	} {
+		var records []Record
+		for i, key := range testcase.keys {
+			records = append(records, NewRecord(key, fmt.Sprintf("testroute%d", i)))
+		}
+		var actual, expected interface{} = testcase.opts.CheckDuplicateKeys(records), testcase.expected
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%q with %+v: expect %v, but %v", testcase.keys, testcase.opts, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package urlrouter
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BindTagName is the name of the struct tag that Bind uses.
+const BindTagName = "urlrouter"
+
+// ErrParamNotFound is returned when the path parameter doesn't exist.
+var ErrParamNotFound = errors.New("path parameter not found")
+
+// Params is a slice of Param that provides typed accessors.
+// The path parameters returned by Lookup can be converted into Params such as `urlrouter.Params(params)`.
+type Params []Param
+
+// Get returns a value of the path parameter with name.
+// If the parameter doesn't exist, it returns an empty string.
+func (ps Params) Get(name string) string {
+	value, _ := ps.lookup(name)
+	return value
+}
+
+// Int returns a value of the path parameter with name as int.
+// It returns a *ParamError if the parameter doesn't exist or the value can't be parsed.
+func (ps Params) Int(name string) (int, error) {
+	n, err := ps.parseInt(name, strconv.IntSize)
+	return int(n), err
+}
+
+// Int64 returns a value of the path parameter with name as int64.
+// It returns a *ParamError if the parameter doesn't exist or the value can't be parsed.
+func (ps Params) Int64(name string) (int64, error) {
+	return ps.parseInt(name, 64)
+}
+
+func (ps Params) parseInt(name string, bitSize int) (int64, error) {
+	value, exists := ps.lookup(name)
+	if !exists {
+		return 0, &ParamError{Name: name, Err: ErrParamNotFound}
+	}
+	n, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: value, Err: err}
+	}
+	return n, nil
+}
+
+func (ps Params) lookup(name string) (string, bool) {
+	for _, param := range ps {
+		if param.Name == name {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+// ParamError represents an error of conversion of a path parameter.
+type ParamError struct {
+	// Name of the path parameter.
+	Name string
+
+	// Value of the path parameter.
+	Value string
+
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error implements the error.Error.
+func (e *ParamError) Error() string {
+	if e.Err == ErrParamNotFound {
+		return fmt.Sprintf("%v: `%v`", e.Err, e.Name)
+	}
+	return fmt.Sprintf("invalid value %q of path parameter `%v`: %v", e.Value, e.Name, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// ParamErrors represents the errors of conversion of path parameters.
+type ParamErrors []*ParamError
+
+// Error implements the error.Error.
+func (e ParamErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors. It makes errors.As to find a *ParamError.
+func (e ParamErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// Bind stores the values of params into the fields of the struct pointed to by dst.
+// A field is associated with the path parameter by the struct tag such as `urlrouter:"id"`.
+// Fields that have no tag or the tag "-" are ignored, and fields whose path parameters don't exist are left as is.
+// The fields of an embedded struct that has no tag are bound as well.
+//
+// A field must be a string, an integer, an unsigned integer, a floating-point number, a bool, time.Duration,
+// a type that implements encoding.TextUnmarshaler such as time.Time (RFC 3339), or a pointer to them.
+// Bind converts all the fields even if some of them fail, and returns ParamErrors that holds all the failures.
+// It returns a non-ParamErrors error if dst isn't a non-nil pointer to a struct or it has an unsupported field.
+func Bind(params []Param, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("urlrouter: Bind requires a non-nil pointer to a struct, but %T", dst)
+	}
+	var errs ParamErrors
+	if err := bindStruct(Params(params), v.Elem(), &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func bindStruct(params Params, v reflect.Value, errs *ParamErrors) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, tagged := field.Tag.Lookup(BindTagName)
+		if !tagged {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := bindStruct(params, v.Field(i), errs); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if name == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return fmt.Errorf("urlrouter: Bind can't set unexported field `%v` of %v", field.Name, t)
+		}
+		value, exists := params.lookup(name)
+		if !exists {
+			continue
+		}
+		if err := bindValue(v.Field(i), value); err != nil {
+			if _, ok := err.(*unsupportedTypeError); ok {
+				return fmt.Errorf("urlrouter: Bind can't set field `%v` of %v: %v", field.Name, t, err)
+			}
+			*errs = append(*errs, &ParamError{Name: name, Value: value, Err: err})
+		}
+	}
+	return nil
+}
+
+type unsupportedTypeError struct {
+	t reflect.Type
+}
+
+func (e *unsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type %v", e.t)
+}
+
+func bindValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := bindValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return &unsupportedTypeError{v.Type()}
+	}
+	return nil
+}
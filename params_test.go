@@ -0,0 +1,162 @@
+package urlrouter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Params_Get(t *testing.T) {
+	params := Params{{"id", "7"}, {"name", "alice"}, {"id", "8"}}
+	for _, testcase := range []struct {
+		name, expected string
+	}{
+		{"id", "7"}, {"name", "alice"}, {"missing", ""},
+	} {
+		if actual := params.Get(testcase.name); actual != testcase.expected {
+			t.Errorf("Get(%q) expects %q, but %q", testcase.name, testcase.expected, actual)
+		}
+	}
+}
+
+func Test_Params_Int(t *testing.T) {
+	params := Params{{"id", "7"}, {"neg", "-1"}, {"name", "alice"}, {"big", "9223372036854775807"}}
+	for _, testcase := range []struct {
+		name     string
+		expected int64
+		err      bool
+	}{
+		{"id", 7, false}, {"neg", -1, false}, {"big", 9223372036854775807, false}, {"name", 0, true}, {"missing", 0, true},
+	} {
+		actual, err := params.Int64(testcase.name)
+		if actual != testcase.expected || (err != nil) != testcase.err {
+			t.Errorf("Int64(%q) expects %v, %v, but %v, %v", testcase.name, testcase.expected, testcase.err, actual, err)
+		}
+		if testcase.name == "big" {
+			continue
+		}
+		n, err := params.Int(testcase.name)
+		if int64(n) != testcase.expected || (err != nil) != testcase.err {
+			t.Errorf("Int(%q) expects %v, %v, but %v, %v", testcase.name, testcase.expected, testcase.err, n, err)
+		}
+	}
+
+	_, err := params.Int("missing")
+	if !errors.Is(err, ErrParamNotFound) {
+		t.Errorf("Expect %v, but %v", ErrParamNotFound, err)
+	}
+	_, err = params.Int("name")
+	var perr *ParamError
+	if !errors.As(err, &perr) || perr.Name != "name" || perr.Value != "alice" {
+		t.Errorf("Expect *ParamError of `name`, but %v", err)
+	}
+}
+
+type bindTestID int
+
+func (id *bindTestID) UnmarshalText(text []byte) error {
+	if !strings.HasPrefix(string(text), "id-") {
+		return errors.New("invalid id")
+	}
+	n, err := Params{{"id", string(text[3:])}}.Int("id")
+	*id = bindTestID(n)
+	return err
+}
+
+type BindTestEmbedded struct {
+	Page uint `urlrouter:"page"`
+}
+
+type bindTestStruct struct {
+	BindTestEmbedded
+	Name     string        `urlrouter:"name"`
+	ID       int64         `urlrouter:"id"`
+	Small    int8          `urlrouter:"small"`
+	Ratio    float64       `urlrouter:"ratio"`
+	Draft    bool          `urlrouter:"draft"`
+	Date     time.Time     `urlrouter:"date"`
+	Timeout  time.Duration `urlrouter:"timeout"`
+	Custom   bindTestID    `urlrouter:"custom"`
+	Optional *int          `urlrouter:"optional"`
+	Missing  string        `urlrouter:"missing"`
+	Ignored  string        `urlrouter:"-"`
+	Untagged string
+}
+
+func Test_Bind(t *testing.T) {
+	var actual bindTestStruct
+	actual.Missing = "default"
+	if err := Bind([]Param{
+		{"name", "alice"}, {"id", "7"}, {"small", "-8"}, {"ratio", "0.5"}, {"draft", "true"},
+		{"date", "2014-01-06T12:34:56Z"}, {"timeout", "1m30s"}, {"custom", "id-9"}, {"optional", "10"},
+		{"page", "2"}, {"Untagged", "x"}, {"-", "x"},
+	}, &actual); err != nil {
+		t.Fatal(err)
+	}
+	optional := 10
+	expected := bindTestStruct{
+		BindTestEmbedded: BindTestEmbedded{Page: 2},
+		Name:             "alice",
+		ID:               7,
+		Small:            -8,
+		Ratio:            0.5,
+		Draft:            true,
+		Date:             time.Date(2014, 1, 6, 12, 34, 56, 0, time.UTC),
+		Timeout:          90 * time.Second,
+		Custom:           9,
+		Optional:         &optional,
+		Missing:          "default",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %+v, but %+v", expected, actual)
+	}
+}
+
+func Test_Bind_withInvalidValues(t *testing.T) {
+	var dst bindTestStruct
+	err := Bind([]Param{
+		{"name", "alice"}, {"id", "alice"}, {"small", "128"}, {"page", "-1"}, {"draft", "yes"},
+		{"date", "2014-01-06"}, {"custom", "9"},
+	}, &dst)
+	errs, ok := err.(ParamErrors)
+	if !ok {
+		t.Fatalf("Expect ParamErrors, but %T: %v", err, err)
+	}
+	var actual []string
+	for _, e := range errs {
+		actual = append(actual, e.Name)
+	}
+	expected := []string{"page", "id", "small", "draft", "date", "custom"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	if dst.Name != "alice" {
+		t.Errorf("valid values must be bound, expect %q, but %q", "alice", dst.Name)
+	}
+	if msg := err.Error(); strings.Count(msg, "\n") != len(expected)-1 {
+		t.Errorf("Expect an error message per line, but %q", msg)
+	}
+}
+
+func Test_Bind_withInvalidDestination(t *testing.T) {
+	var s bindTestStruct
+	var n int
+	var unsupported struct {
+		Values []string `urlrouter:"values"`
+	}
+	var unexported struct {
+		name string `urlrouter:"name"`
+	}
+	for _, dst := range []interface{}{nil, s, &n, (*bindTestStruct)(nil), &unsupported, &unexported} {
+		err := Bind([]Param{{"values", "a"}, {"name", "alice"}}, dst)
+		if err == nil {
+			t.Errorf("%T: Expect error, but nil", dst)
+			continue
+		}
+		if _, ok := err.(ParamErrors); ok {
+			t.Errorf("%T: Expect an error that isn't ParamErrors, but %v", dst, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package radix
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+// Dump implements the urlrouter.Dumper.
+// It writes the nodes of Radix Tree. In the text format, the child nodes are indented deeper than their parent.
+func (r *Radix) Dump(w io.Writer, format urlrouter.DumpFormat) error {
+	d := &dumper{w: w, format: format, syntax: r.opts.SyntaxOrDefault()}
+	switch format {
+	case urlrouter.DumpText:
+		d.dump(r.root, "root", -1, 0)
+	case urlrouter.DumpDOT:
+		d.printf("digraph radix {\n\tnode [shape=box];\n")
+		d.dump(r.root, "root", -1, 0)
+		d.printf("}\n")
+	default:
+		return fmt.Errorf("unsupported dump format %v", format)
+	}
+	return d.err
+}
+
+// dumper writes the nodes of Radix Tree.
+type dumper struct {
+	w      io.Writer
+	format urlrouter.DumpFormat
+	syntax *urlrouter.Syntax
+
+	// Number of the nodes that have been written. It is used as an ID of the node in the DOT format.
+	n int
+
+	// The first error of writing.
+	err error
+}
+
+func (d *dumper) printf(format string, a ...interface{}) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, a...)
+	}
+}
+
+// dump writes nd and its descendants. parent is the ID of the parent node.
+func (d *dumper) dump(nd *node, label string, parent int, depth int) {
+	id := d.n
+	d.n++
+	if nd.isLeaf {
+		label += fmt.Sprintf(" => %v %v", nd.data, nd.paramNames)
+		if nd.priority != 0 {
+			label += fmt.Sprintf(" (priority %d)", nd.priority)
+		}
+	}
+	switch d.format {
+	case urlrouter.DumpText:
+		d.printf("%s%s\n", strings.Repeat("  ", depth), label)
+	case urlrouter.DumpDOT:
+		peripheries := 1
+		if nd.isLeaf {
+			peripheries = 2
+		}
+		d.printf("\tn%d [label=%s, peripheries=%d];\n", id, strconv.Quote(label), peripheries)
+		if parent >= 0 {
+			d.printf("\tn%d -> n%d;\n", parent, id)
+		}
+	}
+	for _, child := range nd.children {
+		d.dump(child, strconv.Quote(child.prefix), id, depth+1)
+	}
+	for _, child := range nd.paramChildren {
+		label := string(d.syntax.ParamChar())
+		if child.constraint != nil {
+			label += child.constraint.String()
+		}
+		d.dump(child, label, id, depth+1)
+	}
+	if nd.wildcardChild != nil {
+		d.dump(nd.wildcardChild, string(d.syntax.WildcardChar()), id, depth+1)
+	}
+}
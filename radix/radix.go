@@ -0,0 +1,323 @@
+// A URL router implemented by Radix Tree.
+package radix
+
+import (
+	"sort"
+
+	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/match"
+)
+
+// Radix represents a URLRouter by Radix Tree.
+type Radix struct {
+	root *node
+
+	// Maximum priority of the records.
+	maxPriority int
+
+	opts urlrouter.Options
+}
+
+// New returns a new Radix.
+func New() *Radix {
+	return &Radix{root: &node{}}
+}
+
+// Lookup returns result data of lookup from Radix routing table by given path.
+func (r *Radix) Lookup(path string) (data interface{}, params []urlrouter.Param) {
+	return r.LookupInto(path, nil)
+}
+
+// LookupInto is the same as Lookup, but path parameters are appended to dst[:0].
+// It doesn't allocate if the capacity of dst is enough to hold path parameters and
+// all records that match path have the maximum Priority in the routing table.
+func (r *Radix) LookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	data, params, _ = urlrouter.LookupFixed(path, r.opts.TrailingSlash, dst, r.lookupInto)
+	return data, params
+}
+
+// LookupTrailingSlash implements the urlrouter.TrailingSlashLookuper.
+func (r *Radix) LookupTrailingSlash(path string) (data interface{}, params []urlrouter.Param, fixed string) {
+	return urlrouter.LookupFixed(path, r.opts.TrailingSlash, nil, r.lookupInto)
+}
+
+// lookupInto looks up path as it is.
+func (r *Radix) lookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	if r.opts.Normalize {
+		path = urlrouter.NormalizePath(path)
+	}
+	m := match.New[*node](r.maxPriority, r.opts)
+	r.root.find(path, dst[:0], &m)
+	nd, matched, found := m.Result()
+	if !found {
+		return nil, dst[:0]
+	}
+	params = append(dst[:0], matched...)
+	for i := range params {
+		params[i].Name = nd.paramNames[i]
+	}
+	return nd.data, params
+}
+
+// SetOptions implements the urlrouter.Configurable.
+func (r *Radix) SetOptions(opts urlrouter.Options) {
+	r.opts = opts
+}
+
+// Build builds Radix routing table from records.
+// Optional segments of keys are expanded by urlrouter.ExpandRecords.
+func (r *Radix) Build(records []urlrouter.Record) error {
+	s := r.opts.SyntaxOrDefault()
+	if err := r.opts.CheckDuplicateKeys(records); err != nil {
+		return err
+	}
+	records, err := s.ExpandRecords(records)
+	if err != nil {
+		return err
+	}
+	records = urlrouter.NormalizeRecords(records, r.opts)
+	r.root, r.maxPriority = &node{}, urlrouter.MaxPriority(records)
+	for _, record := range records {
+		if err := r.root.add(s, record.Key, record.Value, record.Priority); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Walk implements the urlrouter.Walker.
+// The routes are walked in the order of the tree, that is, the static routes are walked in the order of keys,
+// and the routes of path parameters follow the routes that share the prefix of them.
+func (r *Radix) Walk(fn func(key string, value interface{}) error) error {
+	return r.root.walk(r.opts.SyntaxOrDefault(), "", nil, nil, fn)
+}
+
+// node represents a node of Radix Tree.
+type node struct {
+	// Label of the edge from the parent node.
+	// It is empty if the node is a child of path parameter.
+	prefix string
+
+	// First characters of the labels of children.
+	indices []byte
+
+	// Static child nodes that are arranged in the order of indices.
+	children []*node
+
+	// Child nodes of path parameter.
+	// The nodes that have a constraint are ordered by the constraint, and the node that has no constraint is the last.
+	paramChildren []*node
+
+	// Child node of wildcard path parameter.
+	wildcardChild *node
+
+	// Constraint of the path parameter that leads to this node.
+	constraint *urlrouter.Constraint
+
+	data       interface{}
+	priority   int
+	paramNames []string
+	isLeaf     bool
+}
+
+// find looks up the nodes that match path, and passes them to m with params that values of path parameters
+// are appended. Names of params aren't set. It reports whether m stopped the lookup.
+// Static children are preferred, and then the children of path parameter, and then the child of wildcard.
+// The values of wildcard path parameter are tried in the order of urlrouter.WildcardEnds.
+func (nd *node) find(path string, params []urlrouter.Param, m *match.Matcher[*node]) bool {
+	if path == "" {
+		return nd.isLeaf && m.Match(nd, nd.priority, params)
+	}
+	c := path[0]
+	if m.CaseInsensitive() {
+		c = urlrouter.FoldByte(c)
+	}
+	if i := indexByte(nd.indices, c); i >= 0 {
+		if child := nd.children[i]; m.HasPrefix(path, child.prefix) && child.find(path[len(child.prefix):], params, m) {
+			return true
+		}
+	}
+	for _, child := range nd.paramChildren {
+		var buf [8]int
+		for _, i := range m.Syntax().ParamEnds(buf[:0], path, 0, m.CaseInsensitive(), child.hasChild) {
+			value := path[:i]
+			if child.constraint != nil && !child.constraint.Match(value) {
+				continue
+			}
+			if child.find(path[i:], append(params, urlrouter.Param{Value: value}), m) {
+				return true
+			}
+		}
+	}
+	if child := nd.wildcardChild; child != nil {
+		var buf [8]int
+		for _, i := range urlrouter.WildcardEnds(buf[:0], path, 0, m.CaseInsensitive(), m.ShortestWildcard(), child.hasChild) {
+			if child.find(path[i:], append(params, urlrouter.Param{Value: path[:i]}), m) {
+				return true
+			}
+		}
+		// the key that ends with the wildcard path parameter is the last resort.
+		return child.isLeaf && m.Match(child, child.priority, append(params, urlrouter.Param{Value: path}))
+	}
+	return false
+}
+
+// walk calls fn for the routes of nd and its descendants.
+// prefix is the static part of the key after the last path parameter, and statics and params are the parts of
+// the key before it. (see urlrouter.JoinKey)
+func (nd *node) walk(s *urlrouter.Syntax, prefix string, statics, params []string, fn func(key string, value interface{}) error) error {
+	prefix += nd.prefix
+	if nd.isLeaf {
+		if err := fn(urlrouter.JoinKey(append(statics[:len(statics):len(statics)], prefix), params, nd.paramNames), nd.data); err != nil {
+			return err
+		}
+	}
+	for _, child := range nd.children {
+		if err := child.walk(s, prefix, statics, params, fn); err != nil {
+			return err
+		}
+	}
+	statics = append(statics[:len(statics):len(statics)], prefix)
+	for _, child := range nd.paramChildren {
+		param := string(s.ParamChar())
+		if child.constraint != nil {
+			param += child.constraint.String()
+		}
+		if err := child.walk(s, "", statics, append(params[:len(params):len(params)], param), fn); err != nil {
+			return err
+		}
+	}
+	if nd.wildcardChild != nil {
+		return nd.wildcardChild.walk(s, "", statics, append(params[:len(params):len(params)], string(s.WildcardChar())), fn)
+	}
+	return nil
+}
+
+func (nd *node) add(s *urlrouter.Syntax, path string, data interface{}, priority int) error {
+	var paramNames []string
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case s.ParamChar():
+			name, constraint, next, err := s.ParseParam(path, i)
+			if err != nil {
+				return err
+			}
+			paramNames = append(paramNames, name)
+			if nd, err = nd.paramChild(constraint); err != nil {
+				return err
+			}
+			i = next
+		case s.WildcardChar():
+			name, _, next, err := s.ParseParam(path, i)
+			if err != nil {
+				return err
+			}
+			paramNames = append(paramNames, name)
+			if nd.wildcardChild == nil {
+				nd.wildcardChild = &node{}
+			}
+			nd = nd.wildcardChild
+			i = next
+		default:
+			end := i + 1
+			for end < len(path) && !s.IsMetaChar(path[end]) {
+				end++
+			}
+			nd = nd.staticChild(path[i:end])
+			i += len(nd.prefix)
+		}
+	}
+	nd.data, nd.priority, nd.paramNames, nd.isLeaf = data, priority, paramNames, true
+	return nil
+}
+
+// staticChild returns the static child node whose label is the longest common prefix of s and the label.
+// The child node will be created or split if needed.
+func (nd *node) staticChild(s string) *node {
+	i := indexByte(nd.indices, s[0])
+	if i < 0 {
+		child := &node{prefix: s}
+		i = sort.Search(len(nd.indices), func(i int) bool { return nd.indices[i] > s[0] })
+		nd.indices = append(nd.indices, 0)
+		copy(nd.indices[i+1:], nd.indices[i:])
+		nd.indices[i] = s[0]
+		nd.children = append(nd.children, nil)
+		copy(nd.children[i+1:], nd.children[i:])
+		nd.children[i] = child
+		return child
+	}
+	child := nd.children[i]
+	n := commonPrefixLen(child.prefix, s)
+	if n < len(child.prefix) {
+		rest := *child
+		rest.prefix = child.prefix[n:]
+		*child = node{
+			prefix:   child.prefix[:n],
+			indices:  []byte{rest.prefix[0]},
+			children: []*node{&rest},
+		}
+	}
+	return child
+}
+
+// paramChild returns the child node of path parameter that has constraint.
+// A new node will be created if it doesn't exist.
+func (nd *node) paramChild(constraint string) (*node, error) {
+	for _, child := range nd.paramChildren {
+		if child.constraint == nil && constraint == "" || child.constraint != nil && child.constraint.String() == constraint {
+			return child, nil
+		}
+	}
+	child := &node{}
+	if constraint != "" {
+		c, err := urlrouter.NewConstraint(constraint)
+		if err != nil {
+			return nil, err
+		}
+		child.constraint = c
+	}
+	i := sort.Search(len(nd.paramChildren), func(i int) bool {
+		c := nd.paramChildren[i].constraint
+		return c == nil || child.constraint != nil && c.String() > child.constraint.String()
+	})
+	nd.paramChildren = append(nd.paramChildren, nil)
+	copy(nd.paramChildren[i+1:], nd.paramChildren[i:])
+	nd.paramChildren[i] = child
+	return child, nil
+}
+
+// hasChild returns whether nd has the static child node that the label begins with c.
+func (nd *node) hasChild(c byte) bool {
+	return indexByte(nd.indices, c) >= 0
+}
+
+// indexByte returns an index of c in indices, or -1 if c isn't present.
+func indexByte(indices []byte, c byte) int {
+	for i, idx := range indices {
+		if idx == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// commonPrefixLen returns the length of the common prefix of a and b.
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// RadixRouter represents the Router of Radix Tree.
+type RadixRouter struct{}
+
+// New returns a new URLRouter that implemented by Radix Tree.
+func (router *RadixRouter) New() urlrouter.URLRouter {
+	return New()
+}
+
+func init() {
+	urlrouter.Register("radix", &RadixRouter{})
+}
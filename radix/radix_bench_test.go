@@ -0,0 +1,39 @@
+package radix
+
+import (
+	"testing"
+
+	"github.com/naoina/kocha-urlrouter/testutil"
+)
+
+func Benchmark_Radix_Lookup_100(b *testing.B) {
+	testutil.Benchmark_URLRouter_Lookup(b, New(), 100)
+}
+
+func Benchmark_Radix_Lookup_300(b *testing.B) {
+	testutil.Benchmark_URLRouter_Lookup(b, New(), 300)
+}
+
+func Benchmark_Radix_Lookup_700(b *testing.B) {
+	testutil.Benchmark_URLRouter_Lookup(b, New(), 700)
+}
+
+func Benchmark_Radix_LookupInto_static(b *testing.B) {
+	testutil.Benchmark_URLRouter_LookupInto(b, New(), "/path/to/route")
+}
+
+func Benchmark_Radix_LookupInto_param(b *testing.B) {
+	testutil.Benchmark_URLRouter_LookupInto(b, New(), "/path/to/p1/sep/p2")
+}
+
+func Benchmark_Radix_Build_100(b *testing.B) {
+	testutil.Benchmark_URLRouter_Build(b, &RadixRouter{}, 100)
+}
+
+func Benchmark_Radix_Build_300(b *testing.B) {
+	testutil.Benchmark_URLRouter_Build(b, &RadixRouter{}, 300)
+}
+
+func Benchmark_Radix_Build_700(b *testing.B) {
+	testutil.Benchmark_URLRouter_Build(b, &RadixRouter{}, 700)
+}
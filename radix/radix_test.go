@@ -0,0 +1,103 @@
+package radix
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/testutil"
+)
+
+func Test_New(t *testing.T) {
+	r := New()
+
+	actual := reflect.TypeOf(r)
+	expected := reflect.TypeOf(&Radix{})
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_Radix_Lookup(t *testing.T) {
+	testutil.Test_URLRouter_Lookup(t, &RadixRouter{})
+}
+
+func Test_Radix_Lookup_with_constraints(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_constraints(t, &RadixRouter{})
+}
+
+func Test_Radix_Lookup_with_params_in_segment(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_params_in_segment(t, &RadixRouter{})
+}
+
+func Test_Radix_Lookup_with_precedence(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_precedence(t, &RadixRouter{})
+}
+
+func Test_Radix_Lookup_with_optional_segments(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_optional_segments(t, &RadixRouter{})
+}
+
+func Test_Radix_Lookup_with_options(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_options(t, &RadixRouter{})
+}
+
+func Test_Radix_Lookup_with_trailing_slash(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_trailing_slash(t, &RadixRouter{})
+}
+
+func Test_Radix_Lookup_with_syntax(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_syntax(t, &RadixRouter{})
+}
+
+func Test_Radix_Lookup_with_mid_path_wildcards(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_mid_path_wildcards(t, &RadixRouter{})
+}
+
+func Test_Radix_Walk(t *testing.T) {
+	testutil.Test_URLRouter_Walk(t, &RadixRouter{})
+}
+
+func Test_Radix_Dump(t *testing.T) {
+	testutil.Test_URLRouter_Dump(t, &RadixRouter{})
+}
+
+func Test_Radix_HostRouter_Lookup(t *testing.T) {
+	testutil.Test_HostRouter_Lookup(t, &RadixRouter{})
+}
+
+func Test_Radix_LookupInto(t *testing.T) {
+	testutil.Test_URLRouter_LookupInto(t, &RadixRouter{})
+	testutil.Test_URLRouter_LookupInto_allocs(t, &RadixRouter{}, "/path/to/route", "/path/to/p1/sep/p2", "/a/to/b/p1/some/wildcard/params")
+}
+
+func Test_Radix_Lookup_with_many_routes(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_many_routes(t, &RadixRouter{})
+}
+
+func Test_Radix_Build(t *testing.T) {
+	testutil.Test_URLRouter_Build(t, &RadixRouter{})
+}
+
+func Test_node_staticChild(t *testing.T) {
+	root := &node{}
+	for _, key := range []string{"/path/to/route", "/path/to/other", "/path", "/pa", "/user"} {
+		if err := root.add(urlrouter.DefaultSyntax, key, key, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var dump func(nd *node, depth int) []string
+	dump = func(nd *node, depth int) (labels []string) {
+		for _, child := range nd.children {
+			labels = append(labels, fmt.Sprintf("%d:%s", depth, child.prefix))
+			labels = append(labels, dump(child, depth+1)...)
+		}
+		return labels
+	}
+	actual := dump(root, 0)
+	expected := []string{"0:/", "1:pa", "2:th", "3:/to/", "4:other", "4:route", "1:user"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
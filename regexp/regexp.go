@@ -4,26 +4,18 @@ package regexp
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"regexp"
+	"sort"
+	"strconv"
 
 	"github.com/naoina/kocha-urlrouter"
 )
 
-const (
-	defaultParamRegexpStr = `[\w-]+`
-)
-
-var (
-	pathRegexp     = regexp.MustCompile(`/(?:(:[\w-]+)|(\*[\w-/]+)|[\w-]*)`)
-	paramRegexpStr = map[byte]string{
-		':': `[\w-]+`,
-		'*': `[\w-/.]+`,
-	}
-)
-
 // Regexp represents a URLRouter by Regular-Expression.
 type Regexp struct {
 	routes []*route
+	opts   urlrouter.Options
 }
 
 // New returns a new Regexp.
@@ -32,70 +24,424 @@ func New() *Regexp {
 }
 
 // Lookup returns result data of lookup from regexp routing table by given path.
+// Routes are tried in the order of the precedence (see urlrouter.ComparePrecedence), and the first route that matches
+// path and satisfies all constraints of its path parameters is returned.
+// If the route has a wildcard path parameter that is followed by a literal, the following routes that share the
+// wildcard path parameter are also tried because they may match with a better value of it.
 func (re *Regexp) Lookup(path string) (data interface{}, params []urlrouter.Param) {
-	for _, nd := range re.routes {
-		matchesBase := nd.regexp.FindStringSubmatch(path)
-		if len(matchesBase) < 1 {
+	return re.LookupInto(path, nil)
+}
+
+// LookupInto is the same as Lookup, but path parameters are appended to dst[:0].
+// It doesn't allocate for the routes that have no path parameters if the capacity of dst is enough,
+// but matching of the routes that have path parameters allocates in the regexp package.
+func (re *Regexp) LookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	data, params, _ = urlrouter.LookupFixed(path, re.opts.TrailingSlash, dst, re.lookupInto)
+	return data, params
+}
+
+// LookupTrailingSlash implements the urlrouter.TrailingSlashLookuper.
+func (re *Regexp) LookupTrailingSlash(path string) (data interface{}, params []urlrouter.Param, fixed string) {
+	return urlrouter.LookupFixed(path, re.opts.TrailingSlash, nil, re.lookupInto)
+}
+
+// lookupInto looks up path as it is.
+func (re *Regexp) lookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	if re.opts.Normalize {
+		path = urlrouter.NormalizePath(path)
+	}
+	for i, nd := range re.routes {
+		if nd.paramNames == nil {
+			if path == nd.static || re.opts.CaseInsensitive && equalFold(path, nd.static) {
+				return nd.data, dst[:0]
+			}
 			continue
 		}
-		subexpNames := nd.regexp.SubexpNames()[1:]
-		if matches := matchesBase[1:]; len(matches) > 0 {
-			params = make([]urlrouter.Param, len(matches))
-			for i := 0; i < len(matches); i++ {
-				params[i] = urlrouter.Param{Name: subexpNames[i], Value: matches[i]}
-			}
+		params, ends, matched := re.match(nd, path, dst[:0])
+		if !matched {
+			continue
+		}
+		if nd.parts != nil {
+			nd, params = re.best(i, path, params, ends)
+			params = append(dst[:0], params...)
 		}
 		return nd.data, params
 	}
-	return nil, nil
+	return nil, dst[:0]
+}
+
+// match returns the values of path parameters of nd that match path, and the ends of the values of the wildcard
+// path parameters that are followed by a literal. The values are appended to params.
+func (re *Regexp) match(nd *route, path string, params []urlrouter.Param) ([]urlrouter.Param, []int, bool) {
+	matches := nd.regexp.FindStringSubmatchIndex(path)
+	if len(matches) < 1 {
+		return params, nil, false
+	}
+	n := len(params)
+	for i, name := range nd.paramNames {
+		value := path[matches[(i+1)*2]:matches[(i+1)*2+1]]
+		if c := nd.constraints[i]; c != nil && !c.Match(value) {
+			if nd.parts == nil {
+				return params, nil, false
+			}
+			// the other values of the wildcard path parameters may satisfy the constraint.
+			return re.backtrack(nd, path, 0, 0, params[:n], nil)
+		}
+		params = append(params, urlrouter.Param{Name: name, Value: value})
+	}
+	var ends []int
+	for _, i := range nd.wildcards {
+		ends = append(ends, matches[(i+1)*2+1])
+	}
+	return params, ends, true
+}
+
+// backtrack matches path[start:] with nd.parts[k:]. The values of the wildcard path parameters are tried in the
+// order of urlrouter.WildcardEnds until all constraints are satisfied.
+// The values of path parameters are appended to params, and the ends of the values of the wildcard path parameters
+// are appended to ends.
+func (re *Regexp) backtrack(nd *route, path string, start, k int, params []urlrouter.Param, ends []int) ([]urlrouter.Param, []int, bool) {
+	p := &nd.parts[k]
+	matches := p.regexp.FindStringSubmatchIndex(path[start:])
+	if len(matches) < 1 {
+		return params, ends, false
+	}
+	for i := 2; i < len(matches); i += 2 {
+		j, value := len(params), path[start+matches[i]:start+matches[i+1]]
+		if c := nd.constraints[j]; c != nil && !c.Match(value) {
+			return params, ends, false
+		}
+		params = append(params, urlrouter.Param{Name: nd.paramNames[j], Value: value})
+	}
+	if k == len(nd.parts)-1 {
+		return params, ends, true
+	}
+	wildcardStart, name := start+matches[1], nd.paramNames[len(params)]
+	var buf [8]int
+	for _, end := range urlrouter.WildcardEnds(buf[:0], path, wildcardStart, re.opts.CaseInsensitive, re.opts.ShortestWildcard, p.isDelimiter) {
+		param := urlrouter.Param{Name: name, Value: path[wildcardStart:end]}
+		if params, ends, matched := re.backtrack(nd, path, end, k+1, append(params, param), append(ends, end)); matched {
+			return params, ends, true
+		}
+	}
+	return params, ends, false
+}
+
+// best returns the route that the tree-based implementations choose from re.routes[i] that matched path and the
+// following routes of the same priority. They explore the values of a wildcard path parameter before the rest of
+// the keys, so a following route that shares the wildcard path parameter wins if its value ends at a better position.
+func (re *Regexp) best(i int, path string, params []urlrouter.Param, ends []int) (*route, []urlrouter.Param) {
+	nd := re.routes[i]
+	for _, other := range re.routes[i+1:] {
+		if other.priority != nd.priority {
+			break
+		}
+		shared := sharedWildcards(nd, other)
+		if shared == 0 {
+			continue
+		}
+		otherParams, otherEnds, matched := re.match(other, path, nil)
+		if !matched {
+			continue
+		}
+		for k := 0; k < shared; k++ {
+			end, otherEnd := wildcardEnd(ends, k, len(path)), wildcardEnd(otherEnds, k, len(path))
+			if end == otherEnd {
+				continue
+			}
+			if re.endBefore(otherEnd, end, len(path)) {
+				nd, params, ends = other, otherParams, otherEnds
+			}
+			break
+		}
+	}
+	return nd, params
+}
+
+// endBefore reports whether the end a of a value of wildcard path parameter is tried before b. (see
+// urlrouter.WildcardEnds) n is the length of the path, the value that ends at it is the last resort.
+func (re *Regexp) endBefore(a, b, n int) bool {
+	switch {
+	case a == n:
+		return false
+	case b == n:
+		return true
+	case re.opts.ShortestWildcard:
+		return a < b
+	}
+	return a > b
+}
+
+// wildcardEnd returns the k-th of ends, or n if ends doesn't have it, that is, the k-th wildcard path parameter
+// isn't followed by a literal.
+func wildcardEnd(ends []int, k, n int) int {
+	if k < len(ends) {
+		return ends[k]
+	}
+	return n
+}
+
+// sharedWildcards returns the number of the wildcard path parameters in the common prefix of the tokens of a and b.
+func sharedWildcards(a, b *route) (n int) {
+	for i := 0; i < len(a.tokens) && i < len(b.tokens) && a.tokens[i] == b.tokens[i]; i++ {
+		if a.tokens[i].wildcard {
+			n++
+		}
+	}
+	return n
+}
+
+// SetOptions implements the urlrouter.Configurable.
+// If opts.CaseInsensitive is true, the ASCII letters of the static parts of the routes are matched by the character
+// classes of both cases, such as `[aA]`, instead of the `(?i)` flag that folds all letters.
+func (re *Regexp) SetOptions(opts urlrouter.Options) {
+	re.opts = opts
 }
 
 // Build builds regexp routing table from records.
+// Routes are sorted by Priority of records in descending order, and then by the precedence of keys.
+// Optional segments of keys are expanded by urlrouter.ExpandRecords.
 func (re *Regexp) Build(records []urlrouter.Record) error {
-	re.routes = make([]*route, len(records))
+	s := re.opts.SyntaxOrDefault()
+	if err := re.opts.CheckDuplicateKeys(records); err != nil {
+		return err
+	}
+	records, err := s.ExpandRecords(records)
+	if err != nil {
+		return err
+	}
+	records = urlrouter.NormalizeRecords(records, re.opts)
+	routes := make([]*route, len(records))
 	for i, record := range records {
-		route, err := build(record.Key, record.Value)
+		route, err := build(s, record.Key, record.Value, re.opts)
 		if err != nil {
 			return err
 		}
-		re.routes[i] = route
+		route.priority = record.Priority
+		routes[i] = route
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].priority != routes[j].priority {
+			return routes[i].priority > routes[j].priority
+		}
+		return s.ComparePrecedence(routes[i].static, routes[j].static) < 0
+	})
+	re.routes = routes
+	return nil
+}
+
+// Walk implements the urlrouter.Walker.
+// The routes are walked in the order in which they are tried by Lookup.
+func (re *Regexp) Walk(fn func(key string, value interface{}) error) error {
+	for _, route := range re.routes {
+		if err := fn(route.static, route.data); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func build(path string, data interface{}) (*route, error) {
+// Dump implements the urlrouter.Dumper.
+// It writes the routes in the order in which they are tried by Lookup, with their regular expressions.
+// The routes that have no path parameters are compared as strings, their regular expressions aren't used.
+func (re *Regexp) Dump(w io.Writer, format urlrouter.DumpFormat) error {
 	var buf bytes.Buffer
-	dups := make(map[string]bool)
-	for _, paths := range pathRegexp.FindAllStringSubmatch(path, -1) {
-		name := paths[1] + paths[2]
-		if name == "" {
-			// don't have path parameters.
-			buf.WriteString(regexp.QuoteMeta(paths[0]))
+	switch format {
+	case urlrouter.DumpText:
+		for i, route := range re.routes {
+			fmt.Fprintf(&buf, "%d: %v\t%v => %v %v", i, route.static, route.regexp, route.data, route.paramNames)
+			if route.priority != 0 {
+				fmt.Fprintf(&buf, " (priority %d)", route.priority)
+			}
+			buf.WriteByte('\n')
+		}
+	case urlrouter.DumpDOT:
+		buf.WriteString("digraph regexp {\n\tnode [shape=box];\n")
+		for i, route := range re.routes {
+			label := fmt.Sprintf("%v\n%v\n=> %v %v", route.static, route.regexp, route.data, route.paramNames)
+			if route.priority != 0 {
+				label += fmt.Sprintf(" (priority %d)", route.priority)
+			}
+			fmt.Fprintf(&buf, "\tr%d [label=%s];\n", i, strconv.Quote(label))
+			if i > 0 {
+				fmt.Fprintf(&buf, "\tr%d -> r%d [label=\"unmatched\"];\n", i-1, i)
+			}
+		}
+		buf.WriteString("}\n")
+	default:
+		return fmt.Errorf("unsupported dump format %v", format)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func build(s *urlrouter.Syntax, path string, data interface{}, opts urlrouter.Options) (*route, error) {
+	nd := &route{static: path, data: data}
+	// buf is the pattern of the whole of path, and part is the pattern of the part of nd.parts.
+	var buf, part bytes.Buffer
+	var parts []string
+	for i := 0; i < len(path); i++ {
+		if !s.IsMetaChar(path[i]) {
+			buf.WriteString(literalPattern(path[i:i+1], opts.CaseInsensitive))
+			part.WriteString(literalPattern(path[i:i+1], opts.CaseInsensitive))
+			nd.tokens = append(nd.tokens, token{text: path[i : i+1]})
 			continue
 		}
-		var pathReStr string
-		if pathReStr = paramRegexpStr[name[0]]; pathReStr == "" {
-			pathReStr = defaultParamRegexpStr
-		} else {
-			if dups[name] {
-				return nil, fmt.Errorf("path parameter `%v` is duplicated in the key '%v'", name, path)
+		name, constraint, next, err := s.ParseParam(path, i)
+		if err != nil {
+			return nil, err
+		}
+		var c *urlrouter.Constraint
+		if constraint != "" {
+			if c, err = urlrouter.NewConstraint(constraint); err != nil {
+				return nil, err
 			}
-			dups[name] = true
-			name = name[1:] // truncate a meta character.
 		}
-		buf.WriteString(fmt.Sprintf(`/(?P<%s>%s)`, regexp.QuoteMeta(name), pathReStr))
+		switch {
+		case path[i] == s.WildcardChar() && next < len(path):
+			// the value ends at an occurrence of the first character of the literal that follows it.
+			// The other occurrences are tried by backtrack.
+			if opts.ShortestWildcard {
+				buf.WriteString(`((?s:.+?))`)
+			} else {
+				buf.WriteString(`((?s:.+))`)
+			}
+			nd.wildcards = append(nd.wildcards, len(nd.paramNames))
+			nd.parts = append(nd.parts, routePart{delimiter: int(path[next])})
+			parts = append(parts, `^`+part.String())
+			part.Reset()
+		case path[i] == s.WildcardChar():
+			buf.WriteString(`((?s:.+))`)
+			part.WriteString(`((?s:.+))`)
+		case next < len(path) && !s.IsSeparator(path[next]):
+			// the value ends at the first occurrence of the first character of the literal that follows it.
+			fmt.Fprintf(&buf, `(%s)`, paramPattern(s, int(path[next]), opts.CaseInsensitive))
+			fmt.Fprintf(&part, `(%s)`, paramPattern(s, int(path[next]), opts.CaseInsensitive))
+		default:
+			fmt.Fprintf(&buf, `(%s)`, paramPattern(s, -1, false))
+			fmt.Fprintf(&part, `(%s)`, paramPattern(s, -1, false))
+		}
+		nd.tokens = append(nd.tokens, token{meta: path[i], text: constraint, wildcard: path[i] == s.WildcardChar()})
+		nd.paramNames = append(nd.paramNames, name)
+		nd.constraints = append(nd.constraints, c)
+		i = next - 1
 	}
-	reg, err := regexp.Compile(fmt.Sprintf(`^%s$`, buf.String()))
-	if err != nil {
+	var err error
+	if nd.regexp, err = regexp.Compile(`^` + buf.String() + `$`); err != nil {
 		return nil, err
 	}
-	return &route{regexp: reg, data: data}, nil
+	if nd.parts == nil {
+		return nd, nil
+	}
+	nd.parts = append(nd.parts, routePart{delimiter: -1})
+	parts = append(parts, `^`+part.String()+`$`)
+	for i, pattern := range parts {
+		if nd.parts[i].regexp, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return nd, nil
+}
+
+// equalFold reports whether path is equal to key that folded by urlrouter.FoldStatic. Only ASCII letters of path are
+// folded as the other implementations do.
+func equalFold(path, key string) bool {
+	if len(path) != len(key) {
+		return false
+	}
+	for i := 0; i < len(path); i++ {
+		if urlrouter.FoldByte(path[i]) != key[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// literalPattern returns a pattern of a byte c of the static part of a key. If caseInsensitive is true, an ASCII
+// letter matches both cases.
+func literalPattern(c string, caseInsensitive bool) string {
+	if lower := urlrouter.FoldByte(c[0]); caseInsensitive && 'a' <= lower && lower <= 'z' {
+		return `[` + string(rune(lower)) + string(rune(lower-'a'+'A')) + `]`
+	}
+	return regexp.QuoteMeta(c)
+}
+
+// paramPattern returns a pattern of a value of path parameter that contains neither the separators of s nor
+// delimiter. delimiter is -1 if the path parameter isn't followed by a literal.
+// If caseInsensitive is true and delimiter is an ASCII letter, the value contains neither case of it.
+func paramPattern(s *urlrouter.Syntax, delimiter int, caseInsensitive bool) string {
+	if s.Separators() == "" && delimiter < 0 {
+		return `(?s:.+)`
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`[^`)
+	for _, c := range []byte(s.Separators()) {
+		if c == ']' || c == '^' || c == '-' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(c)
+	}
+	if delimiter >= 0 {
+		if lower := urlrouter.FoldByte(byte(delimiter)); caseInsensitive && 'a' <= lower && lower <= 'z' {
+			fmt.Fprintf(&buf, `\x{%x}\x{%x}`, lower, lower-'a'+'A')
+		} else {
+			fmt.Fprintf(&buf, `\x{%x}`, delimiter)
+		}
+	}
+	buf.WriteString(`]+`)
+	return buf.String()
 }
 
 // route represents a regexp route.
 type route struct {
 	regexp *regexp.Regexp
-	data   interface{}
+
+	// Key of the route. It is also used instead of regexp if the route has no path parameters.
+	static string
+
+	data        interface{}
+	priority    int
+	paramNames  []string
+	constraints []*urlrouter.Constraint
+
+	// Tokens of the key that are compared with the other routes that share a wildcard path parameter.
+	tokens []token
+
+	// Indexes of paramNames of the wildcard path parameters that are followed by a literal.
+	wildcards []int
+
+	// Parts of the key that are split at the wildcard path parameters that are followed by a literal.
+	// They are used to try the other values of the wildcard path parameters if a constraint isn't satisfied.
+	// It is nil if the route has no such wildcard path parameters.
+	parts []routePart
+}
+
+// routePart represents a part of the key of a route.
+type routePart struct {
+	// Regular expression of the part that is matched from the beginning of the rest of the path.
+	// It isn't anchored at the end except the last part.
+	regexp *regexp.Regexp
+
+	// The first character of the literal that follows the wildcard path parameter after the part, or -1 for the
+	// last part.
+	delimiter int
+}
+
+// isDelimiter returns whether c is the delimiter of p.
+func (p *routePart) isDelimiter(c byte) bool {
+	return int(c) == p.delimiter
+}
+
+// token represents a static character or a path parameter of the key of a route.
+type token struct {
+	// Prefix of the path parameter, or 0 if the token is a static character.
+	meta byte
+
+	// Static character, or constraint of the path parameter.
+	text string
+
+	// Whether the token is a wildcard path parameter.
+	wildcard bool
 }
 
 // RegexpRouter represents the Router of Regular-Expression.
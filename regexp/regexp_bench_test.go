@@ -18,6 +18,10 @@ func BenchmarkRegexp_Lookup_700(b *testing.B) {
 	testutil.Benchmark_URLRouter_Lookup(b, New(), 700)
 }
 
+func Benchmark_Regexp_LookupInto_static(b *testing.B) {
+	testutil.Benchmark_URLRouter_LookupInto(b, New(), "/path/to/route")
+}
+
 func Benchmark_Regexp_Build_100(b *testing.B) {
 	testutil.Benchmark_URLRouter_Build(b, &RegexpRouter{}, 100)
 }
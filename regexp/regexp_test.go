@@ -21,6 +21,55 @@ func Test_Regexp_Lookup(t *testing.T) {
 	testutil.Test_URLRouter_Lookup(t, &RegexpRouter{})
 }
 
+func Test_Regexp_Lookup_with_constraints(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_constraints(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Lookup_with_params_in_segment(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_params_in_segment(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Lookup_with_precedence(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_precedence(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Lookup_with_optional_segments(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_optional_segments(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Lookup_with_options(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_options(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Lookup_with_trailing_slash(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_trailing_slash(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Lookup_with_syntax(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_syntax(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Lookup_with_mid_path_wildcards(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_mid_path_wildcards(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Walk(t *testing.T) {
+	testutil.Test_URLRouter_Walk(t, &RegexpRouter{})
+}
+
+func Test_Regexp_Dump(t *testing.T) {
+	testutil.Test_URLRouter_Dump(t, &RegexpRouter{})
+}
+
+func Test_Regexp_HostRouter_Lookup(t *testing.T) {
+	testutil.Test_HostRouter_Lookup(t, &RegexpRouter{})
+}
+
+func Test_Regexp_LookupInto(t *testing.T) {
+	testutil.Test_URLRouter_LookupInto(t, &RegexpRouter{})
+	testutil.Test_URLRouter_LookupInto_allocs(t, &RegexpRouter{}, "/path/to/route")
+}
+
 func Test_Regexp_Lookup_with_many_routes(t *testing.T) {
 	testutil.Test_URLRouter_Lookup_with_many_routes(t, &RegexpRouter{})
 }
@@ -0,0 +1,109 @@
+package urlrouter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// URLFor returns a path that built from key by replacing the path parameters in key with params.
+// key is a Key of Record such as "/user/:id" or "/static/*filepath".
+// The order of params doesn't matter. A value of params is embedded as is, it won't be escaped.
+// URLFor returns an error when a parameter in key is missing from params, when params contains a
+// parameter that isn't in key, or when a value can't be held by the parameter.
+// e.g. a value of a path parameter (`:name`) must not be empty, must not contain any separator nor the first
+// character of the literal that follows it such as "-" of "/:from-:to", and must satisfy its constraint if any,
+// and a value of a wildcard path parameter (`*name`) must not be empty.
+// If key has optional segments, the first key expanded by ExpandOptional that can be built with params is used.
+// e.g. "/archive(/:year(/:month))" with only "year" results in "/archive/2014".
+// It is the same as DefaultSyntax.URLFor.
+func URLFor(key string, params []Param) (string, error) {
+	return DefaultSyntax.URLFor(key, params)
+}
+
+// URLFor returns a path that built from key that written in s. See URLFor.
+func (s *Syntax) URLFor(key string, params []Param) (string, error) {
+	keys, err := s.ExpandOptional(key)
+	if err != nil {
+		return "", err
+	}
+	var firstErr error
+	for _, key := range keys {
+		path, err := s.urlFor(key, params)
+		if err == nil {
+			return path, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", firstErr
+}
+
+// urlFor returns a path that built from key that has no optional segments.
+func (s *Syntax) urlFor(key string, params []Param) (string, error) {
+	values := make(map[string]string, len(params))
+	for _, param := range params {
+		if _, exists := values[param.Name]; exists {
+			return "", fmt.Errorf("parameter `%v` is given more than once", param.Name)
+		}
+		values[param.Name] = param.Value
+	}
+	var buf bytes.Buffer
+	for i := 0; i < len(key); i++ {
+		if !s.IsMetaChar(key[i]) {
+			buf.WriteByte(key[i])
+			continue
+		}
+		name, constraint, next, err := s.ParseParam(key, i)
+		if err != nil {
+			return "", err
+		}
+		value, exists := values[name]
+		if !exists {
+			return "", fmt.Errorf("parameter `%v` is missing for the key '%v'", name, key)
+		}
+		if err := s.validateParamValue(key[i], name, value, s.paramDelimiter(key, next)); err != nil {
+			return "", err
+		}
+		if constraint != "" {
+			c, err := NewConstraint(constraint)
+			if err != nil {
+				return "", err
+			}
+			if !c.Match(value) {
+				return "", fmt.Errorf("value of parameter `%v` doesn't satisfy the constraint `%v`: %q", name, constraint, value)
+			}
+		}
+		buf.WriteString(value)
+		delete(values, name)
+		i = next - 1
+	}
+	if len(values) > 0 {
+		names := make([]string, 0, len(values))
+		for _, param := range params {
+			if _, exists := values[param.Name]; exists {
+				names = append(names, param.Name)
+			}
+		}
+		return "", fmt.Errorf("parameters `%v` are not in the key '%v'", strings.Join(names, "`, `"), key)
+	}
+	return buf.String(), nil
+}
+
+// validateParamValue returns an error if value can't be held by the path parameter that prefixed by meta.
+// delimiter is the first character of the literal that follows the path parameter in the key, or -1 if none.
+func (s *Syntax) validateParamValue(meta byte, name, value string, delimiter int) error {
+	if value == "" {
+		return fmt.Errorf("value of parameter `%v` is empty", name)
+	}
+	if meta == s.paramChar {
+		if i := s.NextSeparator(value, 0); i != len(value) {
+			return fmt.Errorf("value of parameter `%v` contains separator %q: %q", name, value[i], value)
+		}
+		if delimiter >= 0 && strings.IndexByte(value, byte(delimiter)) >= 0 {
+			return fmt.Errorf("value of parameter `%v` contains %q that follows the parameter: %q", name, byte(delimiter), value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package urlrouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_URLFor(t *testing.T) {
+	for _, testcase := range []struct {
+		key      string
+		params   []Param
+		expected string
+	}{
+		{"/", nil, "/"},
+		{"/path/to/route", nil, "/path/to/route"},
+		{"/user/:id", []Param{{"id", "777"}}, "/user/777"},
+		{"/:year/:month/:day", []Param{{"day", "06"}, {"year", "2014"}, {"month", "01"}}, "/2014/01/06"},
+		{"/files/:name.:ext", []Param{{"name", "photo"}, {"ext", "png"}}, "/files/photo.png"},
+		{"/static/*filepath", []Param{{"filepath", "path/to/file.css"}}, "/static/path/to/file.css"},
+		{"/user/:id<int>", []Param{{"id", "777"}}, "/user/777"},
+		{"/a/:param/*routepath", []Param{{"param", "p1"}, {"routepath", "some/params"}}, "/a/p1/some/params"},
+		{"/repos/*path/blob", []Param{{"path", "a/b"}}, "/repos/a/b/blob"},
+		{"/archive(/:year(/:month))", []Param{{"year", "2014"}, {"month", "01"}}, "/archive/2014/01"},
+		{"/archive(/:year(/:month))", []Param{{"year", "2014"}}, "/archive/2014"},
+		{"/archive(/:year(/:month))", nil, "/archive"},
+		{"/users/:id?/edit", nil, "/users/edit"},
+		{"/range/:from-:to", []Param{{"from", "a"}, {"to", "b-c"}}, "/range/a-b-c"},
+	} {
+		actual, err := URLFor(testcase.key, testcase.params)
+		if err != nil {
+			t.Errorf("key = %q, params = %v; unexpected error: %v", testcase.key, testcase.params, err)
+			continue
+		}
+		expected := testcase.expected
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("key = %q, params = %v; expect %q, but %q", testcase.key, testcase.params, expected, actual)
+		}
+	}
+}
+
+func Test_URLFor_withInvalidParams(t *testing.T) {
+	for _, testcase := range []struct {
+		key    string
+		params []Param
+	}{
+		{"/user/:id", nil},
+		{"/user/:id", []Param{{"name", "alice"}}},
+		{"/user/:id", []Param{{"id", "1"}, {"name", "alice"}}},
+		{"/user/:id", []Param{{"id", "1"}, {"id", "2"}}},
+		{"/user/:id", []Param{{"id", ""}}},
+		{"/user/:id", []Param{{"id", "1/2"}}},
+		{"/user/:id", []Param{{"id", "1.json"}}},
+		{"/static/*filepath", []Param{{"filepath", ""}}},
+		{"/user/:id<int>", []Param{{"id", "alice"}}},
+		{"/user/:id<unknown>", []Param{{"id", "1"}}},
+		{"/path/to/route", []Param{{"id", "1"}}},
+		{"/archive(/:year(/:month))", []Param{{"month", "01"}}},
+		{"/archive(/:year", nil},
+		{"/range/:from-:to", []Param{{"from", "a-b"}, {"to", "c"}}},
+	} {
+		if actual, err := URLFor(testcase.key, testcase.params); err == nil {
+			t.Errorf("key = %q, params = %v; expect error, but returned %q", testcase.key, testcase.params, actual)
+		}
+	}
+}
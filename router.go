@@ -1,6 +1,9 @@
 package urlrouter
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 const (
 	ParamCharacter    = ':'
@@ -10,6 +13,8 @@ const (
 var routers map[string]Router
 
 // URLRouter is an interface that must be implemented by a URL router.
+// When multiple records match a path, all implementations choose the record that has the highest Priority, and
+// then the record that has the key of the highest precedence. (see ComparePrecedence)
 type URLRouter interface {
 	// Lookup returns data and path parameters that associated with path.
 	// params is a slice of the Param that arranged in the order in which parameters appeared.
@@ -21,6 +26,23 @@ type URLRouter interface {
 	Build(records []Record) error
 }
 
+// BufferedLookuper is an interface that may be implemented by a URLRouter to lookup without allocation.
+type BufferedLookuper interface {
+	// LookupInto is the same as Lookup of URLRouter, but path parameters are appended to dst[:0].
+	// It must not allocate if the capacity of dst is enough to hold path parameters.
+	LookupInto(path string, dst []Param) (data interface{}, params []Param)
+}
+
+// LookupInto looks up path by router with dst as a buffer of path parameters.
+// If router doesn't implement BufferedLookuper, path parameters that returned by Lookup are appended to dst[:0].
+func LookupInto(router URLRouter, path string, dst []Param) (data interface{}, params []Param) {
+	if r, ok := router.(BufferedLookuper); ok {
+		return r.LookupInto(path, dst)
+	}
+	data, params = router.Lookup(path)
+	return data, append(dst[:0], params...)
+}
+
 // param represents a name and value of path parameter.
 type Param struct {
 	Name  string
@@ -47,13 +69,29 @@ func NewURLRouter(name string) URLRouter {
 	return router.New()
 }
 
+// RouterNames returns the names of the registered Routers in ascending order.
+func RouterNames() []string {
+	names := make([]string, 0, len(routers))
+	for name := range routers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Record represents a record data for a router construction.
+// Use NewRecord, or a composite literal with the field names to set Priority, because fields may be added.
 type Record struct {
 	// Key for a router construction.
 	Key string
 
 	// Result value for Key.
 	Value interface{}
+
+	// Priority of the record.
+	// When multiple records match a path, the record that has the highest Priority wins regardless of the precedence
+	// of the keys. Default is 0.
+	Priority int
 }
 
 // NewRecord returns a new Record.
@@ -64,6 +102,99 @@ func NewRecord(key string, value interface{}) Record {
 	}
 }
 
+// MaxPriority returns the maximum Priority of records, or 0 if records is empty.
+func MaxPriority(records []Record) int {
+	if len(records) == 0 {
+		return 0
+	}
+	max := records[0].Priority
+	for _, record := range records[1:] {
+		if record.Priority > max {
+			max = record.Priority
+		}
+	}
+	return max
+}
+
+// ComparePrecedence compares the precedence of key a and key b.
+// It returns a negative number if a precedes b, a positive number if b precedes a, or zero if they are the same.
+//
+// When multiple records match a path, the implementations of URLRouter choose the record by the following rules.
+//
+//  1. The record that has the highest Priority wins.
+//  2. Otherwise, keys are compared from the beginning, and the key that has the higher precedence at the first
+//     position where they differ wins. The order of precedence is a static string, a path parameter that has a
+//     constraint, a path parameter that has no constraint and a wildcard path parameter. Path parameters that have
+//     different constraints are ordered by the constraints.
+//
+// As a result, a static route is preferred to any parameterized route, and a route that has a path parameter
+// at a deeper position is preferred to a route that has it at a shallower position.
+// A value of a path parameter or a wildcard path parameter must not be empty.
+//
+// A segment can have multiple path parameters and literals such as "/:from-:to", "/v:version" and "/img_:id.png".
+// A value of a path parameter never contains a separator ('/' or '.', or the separators of Syntax in Options).
+// If a path parameter is followed by a literal other than a separator, such as "-" of "/:from-:to", the value never
+// contains the first character of the literal either, that is, the value ends at its first occurrence.
+// Otherwise the value is the rest of the segment.
+// e.g. "/:from-:to" matches "/a-b-c" with from "a" and to "b-c". (see ParamEnds)
+//
+// A value of a wildcard path parameter can contain separators. A wildcard path parameter can be followed by a
+// literal such as "/blob" of "/repos/*path/blob", then the value ends at an occurrence of the first character of the
+// literal. The occurrences are tried from the last one, that is, the longest value wins, or from the first one if
+// Options.ShortestWildcard is true. A key that ends with the wildcard path parameter is tried after them.
+// e.g. "/repos/*path/blob" matches "/repos/a/b/blob" with path "a/b". (see WildcardEnds)
+//
+// It is the same as DefaultSyntax.ComparePrecedence.
+func ComparePrecedence(a, b string) int {
+	return DefaultSyntax.ComparePrecedence(a, b)
+}
+
+// ComparePrecedence compares the precedence of key a and key b that written in s. See ComparePrecedence.
+func (s *Syntax) ComparePrecedence(a, b string) int {
+	i, j := 0, 0
+	wildcard := false
+	for i < len(a) && j < len(b) {
+		rankA, nextA, textA := s.precedenceToken(a, i)
+		rankB, nextB, textB := s.precedenceToken(b, j)
+		if rankA != rankB {
+			return rankA - rankB
+		}
+		if textA != textB {
+			if textA < textB {
+				return -1
+			}
+			return 1
+		}
+		i, j, wildcard = nextA, nextB, rankA == wildcardRank
+	}
+	if wildcard {
+		// a wildcard path parameter at the end of the key is the last resort.
+		return (len(b) - j) - (len(a) - i)
+	}
+	return (len(a) - i) - (len(b) - j)
+}
+
+// wildcardRank is the rank of a wildcard path parameter in precedenceToken.
+const wildcardRank = 3
+
+// precedenceToken returns the rank of the token that starts at key[i], an index of the next token and the text
+// that is compared in the same rank.
+func (s *Syntax) precedenceToken(key string, i int) (rank, next int, text string) {
+	if !s.IsMetaChar(key[i]) {
+		return 0, i + 1, key[i : i+1]
+	}
+	_, constraint, next, err := s.ParseParam(key, i)
+	switch {
+	case err != nil:
+		return 0, i + 1, key[i : i+1]
+	case key[i] == s.wildcardChar:
+		return wildcardRank, next, ""
+	case constraint != "":
+		return 1, next, constraint
+	}
+	return 2, next, ""
+}
+
 func init() {
 	routers = make(map[string]Router)
 }
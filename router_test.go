@@ -83,6 +83,24 @@ func Test_NewURLRouter(t *testing.T) {
 	}
 }
 
+func Test_RouterNames(t *testing.T) {
+	defer func() {
+		routers = make(map[string]Router)
+	}()
+	var actual interface{} = RouterNames()
+	var expected interface{} = []string{}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	routers["router2"] = &testRouter{}
+	routers["router1"] = &testRouter{}
+	actual = RouterNames()
+	expected = []string{"router1", "router2"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
 func Test_NewRecord(t *testing.T) {
 	actual := NewRecord("testkey", 100)
 	expected := Record{Key: "testkey", Value: 100}
@@ -90,3 +108,64 @@ func Test_NewRecord(t *testing.T) {
 		t.Errorf("Expect %v, but %v", expected, actual)
 	}
 }
+
+func Test_MaxPriority(t *testing.T) {
+	for _, testcase := range []struct {
+		records  []Record
+		expected int
+	}{
+		{nil, 0},
+		{[]Record{NewRecord("/a", "testroute0")}, 0},
+		{[]Record{{Key: "/a", Priority: -1}, {Key: "/b", Priority: -2}}, -1},
+		{[]Record{NewRecord("/a", "testroute0"), {Key: "/b", Priority: 3}, {Key: "/c", Priority: 1}}, 3},
+	} {
+		if actual := MaxPriority(testcase.records); actual != testcase.expected {
+			t.Errorf("MaxPriority(%v) expects %v, but %v", testcase.records, testcase.expected, actual)
+		}
+	}
+}
+
+func Test_ComparePrecedence(t *testing.T) {
+	for _, testcase := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"/a", "/a", 0},
+		{"/user/:id", "/user/:name", 0},
+		{"/user/new", "/user/:id", -1},
+		{"/user/:id<int>", "/user/:id", -1},
+		{"/user/:id", "/user/*path", -1},
+		{"/user/new", "/user/*path", -1},
+		{"/user/:id<int>", "/user/:id<uint>", -1},
+		{"/:section/list", "/docs/:page", 1},
+		{"/user/*path", "/user/:id<int>", 1},
+		{"/a", "/a/b", -1},
+	} {
+		actual := ComparePrecedence(testcase.a, testcase.b)
+		if actual < 0 {
+			actual = -1
+		} else if actual > 0 {
+			actual = 1
+		}
+		if actual != testcase.expected {
+			t.Errorf("ComparePrecedence(%q, %q) expects %v, but %v", testcase.a, testcase.b, testcase.expected, actual)
+		}
+	}
+}
+
+func Test_LookupInto(t *testing.T) {
+	r := &staticURLRouter{}
+	if err := r.Build([]Record{NewRecord("/a", "testroute0")}); err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]Param, 1, 4)
+	data, params := LookupInto(r, "/a", dst)
+	var actual, expected interface{} = data, "testroute0"
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	actual, expected = params, dst[:0]
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
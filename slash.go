@@ -0,0 +1,60 @@
+package urlrouter
+
+import "strings"
+
+// TrailingSlashPolicy represents how to treat a path that matches a route only if a trailing slash is added or removed.
+type TrailingSlashPolicy int
+
+const (
+	// TrailingSlashStrict doesn't match such a path.
+	TrailingSlashStrict TrailingSlashPolicy = iota
+
+	// TrailingSlashRedirect matches such a path, and suggests redirecting to the fixed path.
+	// e.g. An HTTP handler should respond with 301 Moved Permanently.
+	TrailingSlashRedirect
+
+	// TrailingSlashIgnore matches such a path as if it was the fixed path.
+	TrailingSlashIgnore
+)
+
+// TrailingSlashLookuper is an interface that may be implemented by a URLRouter to support Options.TrailingSlash.
+type TrailingSlashLookuper interface {
+	// LookupTrailingSlash is the same as Lookup of URLRouter, but also returns the fixed path to redirect to if path
+	// matched a route only after a trailing slash is added or removed and Options.TrailingSlash is
+	// TrailingSlashRedirect. Otherwise fixed is empty.
+	LookupTrailingSlash(path string) (data interface{}, params []Param, fixed string)
+}
+
+// LookupTrailingSlash looks up path by router, and returns the fixed path to redirect to if path matched a route only
+// after a trailing slash is added or removed and the Options.TrailingSlash of router is TrailingSlashRedirect.
+// If router doesn't implement TrailingSlashLookuper, it is the same as Lookup, and fixed is always empty.
+func LookupTrailingSlash(router URLRouter, path string) (data interface{}, params []Param, fixed string) {
+	if r, ok := router.(TrailingSlashLookuper); ok {
+		return r.LookupTrailingSlash(path)
+	}
+	data, params = router.Lookup(path)
+	return data, params, ""
+}
+
+// LookupFixed looks up path by lookup in accordance with policy.
+// If path doesn't match any route, and the path that a trailing slash is added to or removed from path matches a route,
+// it returns the data and path parameters of the route unless policy is TrailingSlashStrict.
+// fixed is the path that matched if policy is TrailingSlashRedirect, otherwise it is empty.
+// It is a helper for the implementations of TrailingSlashLookuper, dst is passed to lookup as is.
+func LookupFixed(path string, policy TrailingSlashPolicy, dst []Param, lookup func(path string, dst []Param) (interface{}, []Param)) (data interface{}, params []Param, fixed string) {
+	if data, params = lookup(path, dst); data != nil || policy == TrailingSlashStrict {
+		return data, params, ""
+	}
+	switch {
+	case path == "/":
+		return nil, params, ""
+	case strings.HasSuffix(path, "/"):
+		fixed = path[:len(path)-1]
+	default:
+		fixed = path + "/"
+	}
+	if data, params = lookup(fixed, dst); data == nil || policy != TrailingSlashRedirect {
+		return data, params, ""
+	}
+	return data, params, fixed
+}
@@ -0,0 +1,95 @@
+package urlrouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_LookupFixed(t *testing.T) {
+	r := &staticURLRouter{}
+	if err := r.Build([]Record{
+		NewRecord("/", "testroute0"),
+		NewRecord("/users", "testroute1"),
+		NewRecord("/docs/", "testroute2"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(path string, dst []Param) (interface{}, []Param) {
+		return r.Lookup(path)
+	}
+	for _, testcase := range []struct {
+		path   string
+		policy TrailingSlashPolicy
+		value  interface{}
+		fixed  string
+	}{
+		{"/", TrailingSlashStrict, "testroute0", ""},
+		{"/users", TrailingSlashStrict, "testroute1", ""},
+		{"/users/", TrailingSlashStrict, nil, ""},
+		{"/docs", TrailingSlashStrict, nil, ""},
+		{"/users", TrailingSlashRedirect, "testroute1", ""},
+		{"/users/", TrailingSlashRedirect, "testroute1", "/users"},
+		{"/docs", TrailingSlashRedirect, "testroute2", "/docs/"},
+		{"/missing", TrailingSlashRedirect, nil, ""},
+		{"/users/", TrailingSlashIgnore, "testroute1", ""},
+		{"/docs", TrailingSlashIgnore, "testroute2", ""},
+		{"", TrailingSlashIgnore, "testroute0", ""},
+		{"", TrailingSlashRedirect, "testroute0", "/"},
+		{"/", TrailingSlashRedirect, "testroute0", ""},
+	} {
+		data, _, fixed := LookupFixed(testcase.path, testcase.policy, nil, lookup)
+		actual := []interface{}{data, fixed}
+		expected := []interface{}{testcase.value, testcase.fixed}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%q with policy %v expects %v, but %v", testcase.path, testcase.policy, expected, actual)
+		}
+	}
+}
+
+type trailingSlashRouter struct{}
+
+func (r *trailingSlashRouter) New() URLRouter {
+	return &trailingSlashURLRouter{}
+}
+
+type trailingSlashURLRouter struct {
+	staticURLRouter
+}
+
+func (r *trailingSlashURLRouter) LookupTrailingSlash(path string) (data interface{}, params []Param, fixed string) {
+	return r.routes[path], nil, "/fixed"
+}
+
+func Test_LookupTrailingSlash(t *testing.T) {
+	records := []Record{NewRecord("/users", "testroute0")}
+	r := &staticURLRouter{}
+	if err := r.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	data, _, fixed := LookupTrailingSlash(r, "/users")
+	var actual interface{} = []interface{}{data, fixed}
+	var expected interface{} = []interface{}{"testroute0", ""}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	lookuper := &trailingSlashURLRouter{}
+	if err := lookuper.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	data, _, fixed = LookupTrailingSlash(lookuper, "/users")
+	actual, expected = []interface{}{data, fixed}, []interface{}{"testroute0", "/fixed"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	ar := NewAtomicRouter(&trailingSlashRouter{})
+	if err := ar.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	data, _, fixed = LookupTrailingSlash(ar, "/users")
+	actual, expected = []interface{}{data, fixed}, []interface{}{"testroute0", "/fixed"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
@@ -0,0 +1,125 @@
+package urlrouter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSyntax is the Syntax of URL paths. It is used by the functions of this package and by the URLRouters that
+// have no Syntax in Options.
+var DefaultSyntax = mustSyntax(ParamCharacter, WildcardCharacter, "/.")
+
+// Syntax represents the characters that have special meanings in keys of the routing table and in paths.
+// A segment of a path is a part of the path between the separators, and a value of a path parameter never
+// contains a separator.
+// The characters of constraints ('<', '>', '(' and ')') and OptionalCharacter are common to all syntaxes.
+//
+//	urlrouter.NewSyntax('+', '#', "/")  // MQTT topic filters such as "sensors/+/temp" and "sensors/#"
+//	urlrouter.NewSyntax(':', '*', ".")  // DNS names such as ":host.example.com" and "*sub.example.com"
+//	urlrouter.NewSyntax(':', '*', "/")  // gRPC methods such as "/:service/:method" that match "/pkg.Service/Method"
+type Syntax struct {
+	paramChar    byte
+	wildcardChar byte
+	separators   string
+
+	// isSeparator[c] reports whether c is a separator.
+	isSeparator [256]bool
+}
+
+// NewSyntax returns a new Syntax that has paramChar as the prefix of path parameters, wildcardChar as the prefix
+// of wildcard path parameters, and the characters of separators as the separators of the segments.
+// All of them must be ASCII punctuations that differ from each other. They must not be the characters of
+// constraints nor OptionalCharacter.
+func NewSyntax(paramChar, wildcardChar byte, separators string) (*Syntax, error) {
+	if err := checkSyntaxChar(paramChar); err != nil {
+		return nil, fmt.Errorf("invalid parameter character %q: %v", paramChar, err)
+	}
+	if err := checkSyntaxChar(wildcardChar); err != nil {
+		return nil, fmt.Errorf("invalid wildcard character %q: %v", wildcardChar, err)
+	}
+	if paramChar == wildcardChar {
+		return nil, fmt.Errorf("parameter character and wildcard character are the same %q", paramChar)
+	}
+	s := &Syntax{paramChar: paramChar, wildcardChar: wildcardChar, separators: separators}
+	for i := 0; i < len(separators); i++ {
+		c := separators[i]
+		if err := checkSyntaxChar(c); err != nil {
+			return nil, fmt.Errorf("invalid separator %q: %v", c, err)
+		}
+		if c == paramChar || c == wildcardChar {
+			return nil, fmt.Errorf("separator %q is used as a meta character", c)
+		}
+		s.isSeparator[c] = true
+	}
+	return s, nil
+}
+
+// mustSyntax is like NewSyntax but panics if the syntax is invalid.
+func mustSyntax(paramChar, wildcardChar byte, separators string) *Syntax {
+	s, err := NewSyntax(paramChar, wildcardChar, separators)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// checkSyntaxChar returns an error if c can't be used as a meta character or a separator.
+func checkSyntaxChar(c byte) error {
+	switch {
+	case c <= ' ' || c >= 0x7f:
+		return fmt.Errorf("must be a printable ASCII character")
+	case isNameChar(c):
+		return fmt.Errorf("must not be a character of names")
+	case strings.IndexByte("<>()\\", c) >= 0 || c == OptionalCharacter:
+		return fmt.Errorf("must not be a character of constraints nor optional segments")
+	}
+	return nil
+}
+
+// ParamChar returns the prefix of path parameters.
+func (s *Syntax) ParamChar() byte {
+	return s.paramChar
+}
+
+// WildcardChar returns the prefix of wildcard path parameters.
+func (s *Syntax) WildcardChar() byte {
+	return s.wildcardChar
+}
+
+// Separators returns the separators of the segments.
+func (s *Syntax) Separators() string {
+	return s.separators
+}
+
+// String returns a description of the Syntax.
+func (s *Syntax) String() string {
+	return fmt.Sprintf("param %q, wildcard %q, separators %q", s.paramChar, s.wildcardChar, s.separators)
+}
+
+// NextSeparator returns an index of next separator in path.
+func (s *Syntax) NextSeparator(path string, start int) int {
+	for start < len(path) && !s.isSeparator[path[start]] {
+		start++
+	}
+	return start
+}
+
+// IsSeparator returns whether c is a separator of the segments of path.
+func (s *Syntax) IsSeparator(c byte) bool {
+	return s.isSeparator[c]
+}
+
+// IsMetaChar returns whether c is a prefix of path parameters or wildcard path parameters.
+func (s *Syntax) IsMetaChar(c byte) bool {
+	return c == s.paramChar || c == s.wildcardChar
+}
+
+// HasMetaChar returns whether key contains any meta character.
+func (s *Syntax) HasMetaChar(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if s.IsMetaChar(key[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package urlrouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_NewSyntax(t *testing.T) {
+	for _, testcase := range []struct {
+		paramChar, wildcardChar byte
+		separators              string
+	}{
+		{':', '*', "/."},
+		{'+', '#', "/"},
+		{':', '*', "."},
+		{'$', '@', "/-"},
+		{':', '*', ""},
+	} {
+		s, err := NewSyntax(testcase.paramChar, testcase.wildcardChar, testcase.separators)
+		if err != nil {
+			t.Errorf("NewSyntax(%q, %q, %q) returns error: %v", testcase.paramChar, testcase.wildcardChar, testcase.separators, err)
+			continue
+		}
+		actual := []interface{}{s.ParamChar(), s.WildcardChar(), s.Separators()}
+		expected := []interface{}{testcase.paramChar, testcase.wildcardChar, testcase.separators}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expect %v, but %v", expected, actual)
+		}
+	}
+
+	for _, testcase := range []struct {
+		paramChar, wildcardChar byte
+		separators              string
+	}{
+		{':', ':', "/"},
+		{'a', '*', "/"},
+		{':', '_', "/"},
+		{'(', '*', "/"},
+		{':', '?', "/"},
+		{' ', '*', "/"},
+		{0xe3, '*', "/"},
+		{':', '*', "/:"},
+		{':', '*', "/*"},
+		{':', '*', "/<"},
+		{':', '*', "/0"},
+		{':', '*', "/\n"},
+	} {
+		if _, err := NewSyntax(testcase.paramChar, testcase.wildcardChar, testcase.separators); err == nil {
+			t.Errorf("NewSyntax(%q, %q, %q) expect error, but nil", testcase.paramChar, testcase.wildcardChar, testcase.separators)
+		}
+	}
+}
+
+func Test_Syntax(t *testing.T) {
+	s, err := NewSyntax('+', '#', "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		actual, expected interface{}
+	}{
+		{s.IsSeparator('/'), true},
+		{s.IsSeparator('.'), false},
+		{s.IsMetaChar('+'), true},
+		{s.IsMetaChar('#'), true},
+		{s.IsMetaChar(':'), false},
+		{s.HasMetaChar("sensors/:id"), false},
+		{s.HasMetaChar("sensors/+id"), true},
+		{s.NextSeparator("a.b/c", 0), 3},
+		{s.ParamNames("sensors/+id/+kind.v+ver/#rest"), []string{"+id", "+kind", "+ver", "#rest"}},
+		{s.ParamNames("/user/:id"), []string(nil)},
+		{s.FoldStatic("Sensors/+Id/Temp"), "sensors/+Id/temp"},
+		{s.ComparePrecedence("sensors/+id", "sensors/#rest") < 0, true},
+		{s.ComparePrecedence("sensors/:id", "sensors/+id") < 0, true},
+	} {
+		if !reflect.DeepEqual(testcase.actual, testcase.expected) {
+			t.Errorf("Expect %v, but %v", testcase.expected, testcase.actual)
+		}
+	}
+
+	keys, err := s.ExpandOptional("sensors/+id(/+kind)")
+	var actual, expected interface{} = []interface{}{keys, err}, []interface{}{[]string{"sensors/+id/+kind", "sensors/+id"}, nil}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	path, err := s.URLFor("sensors/+id/temp", []Param{{"id", "a.b"}})
+	actual, expected = []interface{}{path, err}, []interface{}{"sensors/a.b/temp", nil}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	if _, err := s.URLFor("sensors/+id/temp", []Param{{"id", "a/b"}}); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+
+	err = s.Validate([]Record{
+		NewRecord("sensors/+id", 0),
+		NewRecord("sensors/+name", 1),
+		NewRecord("static/#path/edit", 2),
+		NewRecord("static/#file/view", 3),
+	})
+	var kinds []ConflictKind
+	if conflicts, ok := err.(Conflicts); ok {
+		for _, c := range conflicts {
+			kinds = append(kinds, c.Kind)
+		}
+	}
+	actual, expected = kinds, []ConflictKind{ParamNameMismatch, ParamNameMismatch}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_WithSyntaxValidation(t *testing.T) {
+	s, err := NewSyntax('+', '#', "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []Record{NewRecord("sensors/+id", 0), NewRecord("sensors/+name", 1)}
+	if err := WithValidation(&staticRouter{}).New().Build(records); err != nil {
+		t.Errorf("Expect nil, but %v", err)
+	}
+	if err := WithSyntaxValidation(&staticRouter{}, s).New().Build(records); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+}
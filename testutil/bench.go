@@ -22,6 +22,27 @@ func Benchmark_URLRouter_Lookup(b *testing.B, router urlrouter.URLRouter, n int)
 	}
 }
 
+// Benchmark_URLRouter_LookupInto benchmarks LookupInto with path, and fails if it allocates.
+func Benchmark_URLRouter_LookupInto(b *testing.B, router urlrouter.URLRouter, path string) {
+	b.StopTimer()
+	if err := router.Build(routes()); err != nil {
+		b.Fatal(err)
+	}
+	lookuper := router.(urlrouter.BufferedLookuper)
+	dst := make([]urlrouter.Param, 0, 8)
+	b.ReportAllocs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		if r, _ := lookuper.LookupInto(path, dst); r == nil {
+			b.Fail()
+		}
+	}
+	b.StopTimer()
+	if allocs := testing.AllocsPerRun(100, func() { lookuper.LookupInto(path, dst) }); allocs != 0 {
+		b.Errorf("%q: expect 0 allocs/op, but %v", path, allocs)
+	}
+}
+
 func Benchmark_URLRouter_Build(b *testing.B, router urlrouter.Router, n int) {
 	b.StopTimer()
 	records := makeTestRecords(n)
@@ -0,0 +1,235 @@
+package testutil
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+const (
+	fuzzMaxRecords    = 32
+	fuzzMaxKeyLength  = 64
+	fuzzMaxPathLength = 256
+)
+
+// Fuzz_URLRouter_Lookup is a fuzz target that builds a routing table by every Router in the registry of urlrouter,
+// and checks that the results of Lookup and LookupInto are equal between the routers.
+// The Routers to be compared must be registered (e.g. imported) before calling it.
+//
+// The first argument of the fuzz function is a routing table that the keys are separated by newlines.
+// A key can be prefixed by '+' to increase Priority of the record by 1.
+// The second argument is a path to lookup. In addition, a path that is made from each key by filling the path
+// parameters is looked up, and so are the upper-case paths if CaseInsensitive is set.
+// The third argument is the urlrouter.Options of the routers. The bits 0, 1 and 2 set CaseInsensitive, Normalize
+// and ShortestWildcard, and the bits 3 and 4 choose the Syntax from fuzzSyntaxes.
+// Only the first record of each key is used because the routers choose different records of the same key, but the
+// routing tables that have the other conflicts reported by urlrouter.Validate are compared as well.
+// The keys that differ only in the names of path parameters are regarded as the same.
+// The routing tables that can't be built by any router are skipped.
+func Fuzz_URLRouter_Lookup(f *testing.F) {
+	names := urlrouter.RouterNames()
+	if len(names) < 2 {
+		f.Fatalf("Expect two or more registered routers, but %v", names)
+	}
+	var keys []string
+	for _, record := range routes() {
+		keys = append(keys, record.Key)
+	}
+	for _, seed := range []struct {
+		keys string
+		path string
+		opts byte
+	}{
+		{strings.Join(keys, "\n"), "/path/to/p1/sep/p2", 0},
+		{strings.Join(keys, "\n"), "/a/to/b/p1/some/wildcard/params", 0},
+		{"/\n/:b\n/*wildcard", "/foo/bar", 0},
+		{"/:a/:b/c\n/:a/*w\n/x/:b/d", "/x/y/c", 0},
+		{"/:a/b/:c/d\n/:a/*w\n/:a/b/*v", "/x/b/y/e", 0},
+		{"/files/:name.:ext\n/files/*path\n/files/:name", "/files/a.b.c", 0},
+		{"/user/:id<int>\n/user/:name\n+/:lang/help", "/user/help", 0},
+		{"/:a([a-z]+)/x\n/:b<int>/y\n/*w", "/abc/y", 0},
+		{"/:a-:b\n/:a\n/:a~:b/c\n/v:v-:w<int>\n/:a<int>-x", "/v1~2-3", 0},
+		{"/r/*p/blob\n/r/*p/tree/:ref\n/r/*p\n/*g/-/:id<int>", "/r/a/blob/-/1", 0},
+		{"/g/*p/edit/:n\n/g/*p/:a\n/*d/x.:e", "/g/a/edit/b/x.y", 0},
+		{"/c/*p/:id<int>/*r\n/c/*p.:e", "/c/a/1.b/x/y", 0},
+		{":0\x00", "\x00\x00", 0},
+		{"*0\n*1\n/c(/:id)\n/c\n/:a/x\n/:b/y", "/c/1", 0},
+		{"/Users/:name\n/users/x\n/page/:n<int>px\n/*p.:E", "/USERS/X", 1},
+		{"/caf\u00e9/:name\n/cafe\u0301s", "/cafe%CC%81/a%20b", 2},
+		{"/r/*p/blob\n/r/*p/tree/:ref\n/r/*p", "/r/a/blob/b/blob", 4},
+		{"sensors/$id/temp\nsensors/#\n$/$/status\nlights/$(/$attr)", "sensors/a.b/temp", 8},
+		{":host.example.com\n*sub.example.com\n:host.:zone.example.com", "a.b.example.com", 16},
+		{"/:service/:method\n/:service/*rest\n/grpc.health.v1.Health/Check", "/pkg.S/M", 24},
+		{"Sensors/$Id/Temp\nsensors/#rest/x", "SENSORS/A/B/X", 15},
+	} {
+		f.Add(seed.keys, seed.path, seed.opts)
+	}
+	f.Fuzz(func(t *testing.T, keys, path string, bits byte) {
+		opts := fuzzOptions(bits)
+		records := fuzzRecords(keys, opts)
+		if records == nil || len(path) > fuzzMaxPathLength {
+			t.Skip()
+		}
+		routers := make([]urlrouter.URLRouter, len(names))
+		for i, name := range names {
+			routers[i] = urlrouter.NewURLRouter(name)
+			c, ok := routers[i].(urlrouter.Configurable)
+			if !ok {
+				t.Skip()
+			}
+			c.SetOptions(opts)
+			if err := routers[i].Build(records); err != nil {
+				t.Skip()
+			}
+		}
+		paths := []string{path}
+		for _, record := range records {
+			paths = append(paths, fillParams(opts.SyntaxOrDefault(), record.Key))
+		}
+		if opts.CaseInsensitive {
+			for _, path := range paths {
+				paths = append(paths, strings.ToUpper(path))
+			}
+		}
+		dst := make([]urlrouter.Param, 0, 4)
+		for _, path := range paths {
+			expected, expectedParams := routers[0].Lookup(path)
+			for i, r := range routers {
+				actual, actualParams := r.Lookup(path)
+				if !reflect.DeepEqual(actual, expected) || !equalParams(actualParams, expectedParams) {
+					t.Fatalf("records = %q with %+v; Lookup(%q) by %v returns %v %v, but by %v returns %v %v",
+						keys, opts, path, names[0], expected, expectedParams, names[i], actual, actualParams)
+				}
+				actual, actualParams = urlrouter.LookupInto(r, path, dst)
+				if !reflect.DeepEqual(actual, expected) || !equalParams(actualParams, expectedParams) {
+					t.Fatalf("records = %q with %+v; LookupInto(%q) by %v returns %v %v, but Lookup returns %v %v",
+						keys, opts, path, names[i], actual, actualParams, expected, expectedParams)
+				}
+			}
+		}
+	})
+}
+
+// fuzzSyntaxes are the syntaxes of the keys that are chosen by the options of Fuzz_URLRouter_Lookup.
+var fuzzSyntaxes = [...]*urlrouter.Syntax{
+	urlrouter.DefaultSyntax,
+	mustSyntax('$', '#', "/"),
+	mustSyntax(':', '*', "."),
+	mustSyntax(':', '*', "/"),
+}
+
+func mustSyntax(paramChar, wildcardChar byte, separators string) *urlrouter.Syntax {
+	s, err := urlrouter.NewSyntax(paramChar, wildcardChar, separators)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// fuzzOptions returns the urlrouter.Options that are made from bits. See Fuzz_URLRouter_Lookup.
+func fuzzOptions(bits byte) urlrouter.Options {
+	return urlrouter.Options{
+		CaseInsensitive:  bits&1 != 0,
+		Normalize:        bits&2 != 0,
+		ShortestWildcard: bits&4 != 0,
+		Syntax:           fuzzSyntaxes[bits>>3&3],
+	}
+}
+
+// fuzzRecords returns the records that are made from keys separated by newlines, or nil if keys is too large.
+// The records whose keys are the same as the key of a former record with opts are omitted. (see keyShapes)
+func fuzzRecords(keys string, opts urlrouter.Options) []urlrouter.Record {
+	var records []urlrouter.Record
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(keys, "\n") {
+		var priority int
+		for ; strings.HasPrefix(key, "+"); key = key[1:] {
+			priority++
+		}
+		if key == "" {
+			continue
+		}
+		if len(key) > fuzzMaxKeyLength || len(records) >= fuzzMaxRecords {
+			return nil
+		}
+		shapes := keyShapes(key, opts)
+		duplicated := false
+		for _, shape := range shapes {
+			duplicated = duplicated || seen[shape]
+			seen[shape] = true
+		}
+		if duplicated {
+			continue
+		}
+		records = append(records, urlrouter.Record{
+			Key:      key,
+			Value:    fmt.Sprintf("testroute%d", len(records)),
+			Priority: priority,
+		})
+	}
+	return records
+}
+
+// keyShapes returns the keys that are expanded from key by ExpandOptional of the Syntax of opts, prepared by
+// urlrouter.NormalizeRecords, and the names of path parameters are removed from, or key as is if key can't be parsed.
+func keyShapes(key string, opts urlrouter.Options) []string {
+	s := opts.SyntaxOrDefault()
+	keys, err := s.ExpandOptional(key)
+	if err != nil {
+		return []string{key}
+	}
+	for i, k := range keys {
+		k = urlrouter.NormalizeRecords([]urlrouter.Record{{Key: k}}, opts)[0].Key
+		var buf bytes.Buffer
+		for j := 0; j < len(k); j++ {
+			if !s.IsMetaChar(k[j]) {
+				buf.WriteByte(k[j])
+				continue
+			}
+			_, constraint, next, err := s.ParseParam(k, j)
+			if err != nil {
+				return []string{key}
+			}
+			buf.WriteByte(k[j])
+			buf.WriteString(constraint)
+			j = next - 1
+		}
+		keys[i] = buf.String()
+	}
+	return keys
+}
+
+// fillParams returns a path that is made from key written in s by filling the path parameters with values.
+// It returns key as is if key can't be parsed.
+func fillParams(s *urlrouter.Syntax, key string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(key); i++ {
+		if !s.IsMetaChar(key[i]) {
+			buf.WriteByte(key[i])
+			continue
+		}
+		_, _, next, err := s.ParseParam(key, i)
+		if err != nil {
+			return key
+		}
+		if key[i] == s.WildcardChar() {
+			buf.WriteString("1" + s.Separators()[:1] + "a")
+		} else {
+			buf.WriteString("1")
+		}
+		i = next - 1
+	}
+	return buf.String()
+}
+
+// equalParams returns whether a and b are equal. nil and an empty slice are regarded as the same.
+func equalParams(a, b []urlrouter.Param) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
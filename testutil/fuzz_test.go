@@ -0,0 +1,15 @@
+package testutil_test
+
+import (
+	"testing"
+
+	_ "github.com/naoina/kocha-urlrouter/doublearray"
+	_ "github.com/naoina/kocha-urlrouter/radix"
+	_ "github.com/naoina/kocha-urlrouter/regexp"
+	"github.com/naoina/kocha-urlrouter/testutil"
+	_ "github.com/naoina/kocha-urlrouter/tst"
+)
+
+func FuzzLookup(f *testing.F) {
+	testutil.Fuzz_URLRouter_Lookup(f)
+}
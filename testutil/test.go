@@ -1,9 +1,12 @@
 package testutil
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,17 +15,17 @@ import (
 
 func routes() []urlrouter.Record {
 	return []urlrouter.Record{
-		{"/", "testroute0"},
-		{"/path/to/route", "testroute1"},
-		{"/path/to/other", "testroute2"},
-		{"/path/to/route/a", "testroute3"},
-		{"/path/to/:param", "testroute4"},
-		{"/path/to/wildcard/*routepath", "testroute5"},
-		{"/path/to/:param1/:param2", "testroute6"},
-		{"/path/to/:param1/sep/:param2", "testroute7"},
-		{"/:year/:month/:day", "testroute8"},
-		{"/user/:id", "testroute9"},
-		{"/a/to/b/:param/*routepath", "testroute10"},
+		urlrouter.NewRecord("/", "testroute0"),
+		urlrouter.NewRecord("/path/to/route", "testroute1"),
+		urlrouter.NewRecord("/path/to/other", "testroute2"),
+		urlrouter.NewRecord("/path/to/route/a", "testroute3"),
+		urlrouter.NewRecord("/path/to/:param", "testroute4"),
+		urlrouter.NewRecord("/path/to/wildcard/*routepath", "testroute5"),
+		urlrouter.NewRecord("/path/to/:param1/:param2", "testroute6"),
+		urlrouter.NewRecord("/path/to/:param1/sep/:param2", "testroute7"),
+		urlrouter.NewRecord("/:year/:month/:day", "testroute8"),
+		urlrouter.NewRecord("/user/:id", "testroute9"),
+		urlrouter.NewRecord("/a/to/b/:param/*routepath", "testroute10"),
 	}
 }
 
@@ -70,9 +73,9 @@ func Test_URLRouter_Lookup(t *testing.T, router urlrouter.Router) {
 	runTest(routes(), testcases)
 
 	records := []urlrouter.Record{
-		{"/", "testroute0"},
-		{"/:b", "testroute1"},
-		{"/*wildcard", "testroute2"},
+		urlrouter.NewRecord("/", "testroute0"),
+		urlrouter.NewRecord("/:b", "testroute1"),
+		urlrouter.NewRecord("/*wildcard", "testroute2"),
 	}
 	testcases = []*testcase{
 		{"/", "testroute0", nil},
@@ -82,12 +85,683 @@ func Test_URLRouter_Lookup(t *testing.T, router urlrouter.Router) {
 	runTest(records, testcases)
 }
 
+func Test_URLRouter_Lookup_with_constraints(t *testing.T, router urlrouter.Router) {
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/user/:id<int>", "testroute0"),
+		urlrouter.NewRecord("/user/:name", "testroute1"),
+		urlrouter.NewRecord("/file/:name([a-z]+).:ext", "testroute2"),
+		urlrouter.NewRecord("/file/:id<int>.:ext", "testroute3"),
+		urlrouter.NewRecord("/file/*path", "testroute4"),
+		urlrouter.NewRecord("/color/:hex([0-9a-f]{6})", "testroute5"),
+		urlrouter.NewRecord("/date/:year([0-9]{4})/:month<uint>", "testroute6"),
+		urlrouter.NewRecord("/post/:id<int>/edit", "testroute7"),
+		urlrouter.NewRecord("/post/:name/view", "testroute8"),
+	}
+	r := router.New()
+	if err := r.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+	}{
+		{"/user/123", "testroute0", []urlrouter.Param{{"id", "123"}}},
+		{"/user/-5", "testroute0", []urlrouter.Param{{"id", "-5"}}},
+		{"/user/abc", "testroute1", []urlrouter.Param{{"name", "abc"}}},
+		{"/file/abc.png", "testroute2", []urlrouter.Param{{"name", "abc"}, {"ext", "png"}}},
+		{"/file/12.png", "testroute3", []urlrouter.Param{{"id", "12"}, {"ext", "png"}}},
+		{"/file/Abc.png", "testroute4", []urlrouter.Param{{"path", "Abc.png"}}},
+		{"/color/00ff00", "testroute5", []urlrouter.Param{{"hex", "00ff00"}}},
+		{"/color/00FF00", nil, nil},
+		{"/color/00ff0", nil, nil},
+		{"/date/2014/01", "testroute6", []urlrouter.Param{{"year", "2014"}, {"month", "01"}}},
+		{"/date/14/01", nil, nil},
+		{"/date/2014/jan", nil, nil},
+		{"/post/1/edit", "testroute7", []urlrouter.Param{{"id", "1"}}},
+		{"/post/1/view", "testroute8", []urlrouter.Param{{"name", "1"}}},
+		{"/post/a/edit", nil, nil},
+	} {
+		actual, params := r.Lookup(testcase.path)
+		if !reflect.DeepEqual(actual, testcase.value) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.value, actual)
+		}
+		if !reflect.DeepEqual(params, testcase.params) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.params, params)
+		}
+	}
+}
+
+func Test_URLRouter_Lookup_with_params_in_segment(t *testing.T, router urlrouter.Router) {
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/v:version/x", "testroute0"),
+		urlrouter.NewRecord("/range/:from-:to", "testroute1"),
+		urlrouter.NewRecord("/img_:id.png", "testroute2"),
+		urlrouter.NewRecord("/files/:name.:ext", "testroute3"),
+		urlrouter.NewRecord("/range/:from-:to<int>", "testroute4"),
+		urlrouter.NewRecord("/:lang-:region/help", "testroute5"),
+		urlrouter.NewRecord("/page/:n<int>px", "testroute6"),
+		urlrouter.NewRecord("/tag/:name", "testroute7"),
+		urlrouter.NewRecord("/tag/:name<int>-x", "testroute8"),
+		urlrouter.NewRecord("/t/:a-:b", "testroute9"),
+		urlrouter.NewRecord("/t/:a~:b", "testroute10"),
+	}
+	r := router.New()
+	if err := r.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+	}{
+		{"/v1/x", "testroute0", []urlrouter.Param{{"version", "1"}}},
+		{"/v/x", nil, nil},
+		{"/range/a-b", "testroute1", []urlrouter.Param{{"from", "a"}, {"to", "b"}}},
+		{"/range/1-2", "testroute4", []urlrouter.Param{{"from", "1"}, {"to", "2"}}},
+		{"/range/a-b-c", "testroute1", []urlrouter.Param{{"from", "a"}, {"to", "b-c"}}},
+		{"/range/-b", nil, nil},
+		{"/range/a-", nil, nil},
+		{"/img_42.png", "testroute2", []urlrouter.Param{{"id", "42"}}},
+		{"/img_42.jpg", nil, nil},
+		{"/files/a.b", "testroute3", []urlrouter.Param{{"name", "a"}, {"ext", "b"}}},
+		{"/en-us/help", "testroute5", []urlrouter.Param{{"lang", "en"}, {"region", "us"}}},
+		{"/page/12px", "testroute6", []urlrouter.Param{{"n", "12"}}},
+		{"/page/12pt", nil, nil},
+		// a path parameter at the end of the key takes the rest of the segment.
+		{"/tag/a-x", "testroute7", []urlrouter.Param{{"name", "a-x"}}},
+		{"/tag/1-x", "testroute8", []urlrouter.Param{{"name", "1"}}},
+		// the keys are compared by the literals that follow the path parameters, and '-' precedes '~'.
+		{"/t/x~y-z", "testroute9", []urlrouter.Param{{"a", "x~y"}, {"b", "z"}}},
+		{"/t/x~y", "testroute10", []urlrouter.Param{{"a", "x"}, {"b", "y"}}},
+	} {
+		actual, params := r.Lookup(testcase.path)
+		if !reflect.DeepEqual(actual, testcase.value) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.value, actual)
+		}
+		if !reflect.DeepEqual(params, testcase.params) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.params, params)
+		}
+	}
+}
+
+func Test_URLRouter_Lookup_with_precedence(t *testing.T, router urlrouter.Router) {
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/files/*path", "testroute0"),
+		urlrouter.NewRecord("/files/:name", "testroute1"),
+		urlrouter.NewRecord("/files/:id<int>", "testroute2"),
+		urlrouter.NewRecord("/files/new", "testroute3"),
+		urlrouter.NewRecord("/files/:name/raw", "testroute4"),
+		urlrouter.NewRecord("/:section/list", "testroute5"),
+		urlrouter.NewRecord("/docs/:page", "testroute6"),
+		{Key: "/admin/*path", Value: "testroute7", Priority: 1},
+		urlrouter.NewRecord("/admin/login", "testroute8"),
+		{Key: "/:lang/help", Value: "testroute9", Priority: 2},
+		urlrouter.NewRecord("/help/:topic", "testroute10"),
+	}
+	r := router.New()
+	if err := r.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+	}{
+		{"/files/new", "testroute3", nil},
+		{"/files/12", "testroute2", []urlrouter.Param{{"id", "12"}}},
+		{"/files/a", "testroute1", []urlrouter.Param{{"name", "a"}}},
+		{"/files/a/raw", "testroute4", []urlrouter.Param{{"name", "a"}}},
+		{"/files/a/b", "testroute0", []urlrouter.Param{{"path", "a/b"}}},
+		{"/files/new/raw", "testroute4", []urlrouter.Param{{"name", "new"}}},
+		{"/files/", nil, nil},
+		{"/docs/list", "testroute6", []urlrouter.Param{{"page", "list"}}},
+		{"/blog/list", "testroute5", []urlrouter.Param{{"section", "blog"}}},
+		{"/admin/login", "testroute7", []urlrouter.Param{{"path", "login"}}},
+		{"/help/help", "testroute9", []urlrouter.Param{{"lang", "help"}}},
+		{"/help/me", "testroute10", []urlrouter.Param{{"topic", "me"}}},
+	} {
+		actual, params := r.Lookup(testcase.path)
+		if !reflect.DeepEqual(actual, testcase.value) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.value, actual)
+		}
+		if !reflect.DeepEqual(params, testcase.params) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.params, params)
+		}
+	}
+}
+
+func Test_URLRouter_Lookup_with_optional_segments(t *testing.T, router urlrouter.Router) {
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/archive(/:year<int>(/:month<int>))", "testroute0"),
+		urlrouter.NewRecord("/users/:id?", "testroute1"),
+		urlrouter.NewRecord("/users/:id?/edit", "testroute2"),
+		urlrouter.NewRecord("/file/:name.:ext?", "testroute3"),
+		urlrouter.NewRecord("/static(/*filepath)", "testroute4"),
+	}
+	r := router.New()
+	if err := r.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+	}{
+		{"/archive", "testroute0", nil},
+		{"/archive/2014", "testroute0", []urlrouter.Param{{"year", "2014"}}},
+		{"/archive/2014/01", "testroute0", []urlrouter.Param{{"year", "2014"}, {"month", "01"}}},
+		{"/archive/new", nil, nil},
+		{"/archive/2014/01/06", nil, nil},
+		{"/users", "testroute1", nil},
+		{"/users/1", "testroute1", []urlrouter.Param{{"id", "1"}}},
+		{"/users/", nil, nil},
+		{"/users/edit", "testroute2", nil},
+		{"/users/1/edit", "testroute2", []urlrouter.Param{{"id", "1"}}},
+		{"/file/a.png", "testroute3", []urlrouter.Param{{"name", "a"}, {"ext", "png"}}},
+		{"/file/a", "testroute3", []urlrouter.Param{{"name", "a"}}},
+		{"/static", "testroute4", nil},
+		{"/static/css/style.css", "testroute4", []urlrouter.Param{{"filepath", "css/style.css"}}},
+	} {
+		actual, params := r.Lookup(testcase.path)
+		if !reflect.DeepEqual(actual, testcase.value) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.value, actual)
+		}
+		if !reflect.DeepEqual(params, testcase.params) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.params, params)
+		}
+	}
+
+	for _, key := range []string{"/archive(/:year", "/users:id?"} {
+		if err := router.New().Build([]urlrouter.Record{urlrouter.NewRecord(key, "testroute0")}); err == nil {
+			t.Errorf("no error returned by invalid optional segment %q", key)
+		}
+	}
+}
+
+func Test_URLRouter_Lookup_with_options(t *testing.T, router urlrouter.Router) {
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/Users/:Name", "testroute0"),
+		urlrouter.NewRecord("/users/:id<int>/Edit", "testroute1"),
+		urlrouter.NewRecord("/static/*FilePath", "testroute2"),
+		urlrouter.NewRecord("/caf\u00e9/:name", "testroute3"),
+		urlrouter.NewRecord("/cafe\u0301s", "testroute4"),
+		urlrouter.NewRecord("/page/:n<int>px", "testroute5"),
+	}
+	for _, testcase := range []struct {
+		opts   urlrouter.Options
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+	}{
+		{urlrouter.Options{}, "/Users/Alice", "testroute0", []urlrouter.Param{{"Name", "Alice"}}},
+		{urlrouter.Options{}, "/USERS/Alice", nil, nil},
+		{urlrouter.Options{}, "/caf%C3%A9/a", nil, nil},
+		{urlrouter.Options{CaseInsensitive: true}, "/USERS/Alice", "testroute0", []urlrouter.Param{{"Name", "Alice"}}},
+		{urlrouter.Options{CaseInsensitive: true}, "/uSeRs/Alice", "testroute0", []urlrouter.Param{{"Name", "Alice"}}},
+		{urlrouter.Options{CaseInsensitive: true}, "/USERS/7/EDIT", "testroute1", []urlrouter.Param{{"id", "7"}}},
+		{urlrouter.Options{CaseInsensitive: true}, "/USERS/x/EDIT", nil, nil},
+		{urlrouter.Options{CaseInsensitive: true}, "/STATIC/CSS/Style.css", "testroute2", []urlrouter.Param{{"FilePath", "CSS/Style.css"}}},
+		{urlrouter.Options{CaseInsensitive: true}, "/PAGE/12PX", "testroute5", []urlrouter.Param{{"n", "12"}}},
+		// only ASCII letters are folded.
+		{urlrouter.Options{CaseInsensitive: true}, "/u\u017fers/Alice", nil, nil},
+		{urlrouter.Options{CaseInsensitive: true}, "/CAF\u00c9/a", nil, nil},
+		{urlrouter.Options{CaseInsensitive: true}, "/cafe\u0301\u017f", nil, nil},
+		{urlrouter.Options{Normalize: true}, "/caf%C3%A9/a%20b", "testroute3", []urlrouter.Param{{"name", "a b"}}},
+		{urlrouter.Options{Normalize: true}, "/cafe%CC%81/a", "testroute3", []urlrouter.Param{{"name", "a"}}},
+		{urlrouter.Options{Normalize: true}, "/cafe\u0301/a", "testroute3", []urlrouter.Param{{"name", "a"}}},
+		{urlrouter.Options{Normalize: true}, "/caf\u00e9s", "testroute4", nil},
+		{urlrouter.Options{Normalize: true}, "/USERS/Alice", nil, nil},
+		{urlrouter.Options{CaseInsensitive: true, Normalize: true}, "/CAF%C3%A9/A", "testroute3", []urlrouter.Param{{"name", "A"}}},
+		{urlrouter.Options{CaseInsensitive: true, Normalize: true}, "/%55sers/Alice", "testroute0", []urlrouter.Param{{"Name", "Alice"}}},
+	} {
+		r := urlrouter.WithOptions(router, testcase.opts).New()
+		if err := r.Build(records); err != nil {
+			t.Fatal(err)
+		}
+		actual, params := r.Lookup(testcase.path)
+		if !reflect.DeepEqual(actual, testcase.value) {
+			t.Errorf("%q with %+v expects %v, but %v", testcase.path, testcase.opts, testcase.value, actual)
+		}
+		if !reflect.DeepEqual(params, testcase.params) {
+			t.Errorf("%q with %+v expects %v, but %v", testcase.path, testcase.opts, testcase.params, params)
+		}
+	}
+
+	// keys that become the same by the options are rejected, because the implementations choose different ones.
+	records = []urlrouter.Record{
+		urlrouter.NewRecord("/Ab", "testroute0"),
+		urlrouter.NewRecord("/..:z<int>/", "testroute1"),
+		urlrouter.NewRecord("/aBA/", "testroute2"),
+		urlrouter.NewRecord("/aB", "testroute3"),
+	}
+	r := urlrouter.WithOptions(router, urlrouter.Options{CaseInsensitive: true}).New()
+	err := r.Build(records)
+	var actual, expected interface{} = err, &urlrouter.ConflictError{Kind: urlrouter.DuplicateKey, Index: 3, Record: records[3], OtherIndex: 0, Other: records[0]}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+	r = urlrouter.WithOptions(router, urlrouter.Options{}).New()
+	if err := r.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	if actual, _ := r.Lookup("/Ab"); actual != "testroute0" {
+		t.Errorf("Expect %v, but %v", "testroute0", actual)
+	}
+}
+
+func Test_URLRouter_Lookup_with_trailing_slash(t *testing.T, router urlrouter.Router) {
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/", "testroute0"),
+		urlrouter.NewRecord("/users/:id", "testroute1"),
+		urlrouter.NewRecord("/docs/", "testroute2"),
+		urlrouter.NewRecord("/static/*filepath/", "testroute3"),
+	}
+	for _, testcase := range []struct {
+		policy urlrouter.TrailingSlashPolicy
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+		fixed  string
+	}{
+		{urlrouter.TrailingSlashStrict, "/users/1", "testroute1", []urlrouter.Param{{"id", "1"}}, ""},
+		{urlrouter.TrailingSlashStrict, "/users/1/", nil, nil, ""},
+		{urlrouter.TrailingSlashStrict, "/docs", nil, nil, ""},
+		{urlrouter.TrailingSlashRedirect, "/users/1", "testroute1", []urlrouter.Param{{"id", "1"}}, ""},
+		{urlrouter.TrailingSlashRedirect, "/users/1/", "testroute1", []urlrouter.Param{{"id", "1"}}, "/users/1"},
+		{urlrouter.TrailingSlashRedirect, "/docs", "testroute2", nil, "/docs/"},
+		{urlrouter.TrailingSlashRedirect, "/static/a/b", "testroute3", []urlrouter.Param{{"filepath", "a/b"}}, "/static/a/b/"},
+		{urlrouter.TrailingSlashRedirect, "/missing/", nil, nil, ""},
+		{urlrouter.TrailingSlashIgnore, "/users/1/", "testroute1", []urlrouter.Param{{"id", "1"}}, ""},
+		{urlrouter.TrailingSlashIgnore, "/docs", "testroute2", nil, ""},
+	} {
+		opts := urlrouter.Options{TrailingSlash: testcase.policy}
+		r := urlrouter.WithOptions(router, opts).New()
+		if err := r.Build(records); err != nil {
+			t.Fatal(err)
+		}
+		actual, params := r.Lookup(testcase.path)
+		if !reflect.DeepEqual(actual, testcase.value) {
+			t.Errorf("%q with %+v expects %v, but %v", testcase.path, opts, testcase.value, actual)
+		}
+		if !reflect.DeepEqual(params, testcase.params) {
+			t.Errorf("%q with %+v expects %v, but %v", testcase.path, opts, testcase.params, params)
+		}
+		_, _, fixed := urlrouter.LookupTrailingSlash(r, testcase.path)
+		if !reflect.DeepEqual(fixed, testcase.fixed) {
+			t.Errorf("%q with %+v expects %q, but %q", testcase.path, opts, testcase.fixed, fixed)
+		}
+	}
+}
+
+func Test_URLRouter_Lookup_with_syntax(t *testing.T, router urlrouter.Router) {
+	newSyntax := func(paramChar, wildcardChar byte, separators string) *urlrouter.Syntax {
+		s, err := urlrouter.NewSyntax(paramChar, wildcardChar, separators)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	mqtt, grpc, dns := newSyntax('+', '#', "/"), newSyntax(':', '*', "/"), newSyntax(':', '*', ".")
+	type lookup struct {
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+	}
+	for _, testcase := range []struct {
+		opts    urlrouter.Options
+		records []urlrouter.Record
+		lookups []lookup
+	}{
+		{urlrouter.Options{Syntax: mqtt}, []urlrouter.Record{
+			urlrouter.NewRecord("sensors/+id/temp", "testroute0"),
+			urlrouter.NewRecord("sensors/#rest", "testroute1"),
+			urlrouter.NewRecord("sensors/room:1/temp", "testroute2"),
+			urlrouter.NewRecord("devices/+id", "testroute3"),
+			urlrouter.NewRecord("lights/+id(/+attr)", "testroute4"),
+		}, []lookup{
+			{"sensors/a1/temp", "testroute0", []urlrouter.Param{{"id", "a1"}}},
+			{"sensors/a1/humidity", "testroute1", []urlrouter.Param{{"rest", "a1/humidity"}}},
+			{"sensors/room:1/temp", "testroute2", nil},
+			{"devices/lamp.kitchen", "testroute3", []urlrouter.Param{{"id", "lamp.kitchen"}}},
+			{"devices/lamp/on", nil, nil},
+			{"lights/lamp/on", "testroute4", []urlrouter.Param{{"id", "lamp"}, {"attr", "on"}}},
+			{"lights/lamp", "testroute4", []urlrouter.Param{{"id", "lamp"}}},
+		}},
+		// the unnamed path parameters are named by their positions in the keys.
+		{urlrouter.Options{Syntax: mqtt}, []urlrouter.Record{
+			urlrouter.NewRecord("sensors/+/temp", "testroute0"),
+			urlrouter.NewRecord("sensors/#", "testroute1"),
+			urlrouter.NewRecord("+/+/status", "testroute2"),
+		}, []lookup{
+			{"sensors/a1/temp", "testroute0", []urlrouter.Param{{"0", "a1"}}},
+			{"sensors/a1/humidity", "testroute1", []urlrouter.Param{{"0", "a1/humidity"}}},
+			{"devices/lamp/status", "testroute2", []urlrouter.Param{{"0", "devices"}, {"1", "lamp"}}},
+		}},
+		{urlrouter.Options{Syntax: mqtt, CaseInsensitive: true}, []urlrouter.Record{
+			urlrouter.NewRecord("Sensors/+Id/Temp", "testroute0"),
+		}, []lookup{
+			{"SENSORS/A1/TEMP", "testroute0", []urlrouter.Param{{"Id", "A1"}}},
+		}},
+		{urlrouter.Options{Syntax: grpc}, []urlrouter.Record{
+			urlrouter.NewRecord("/grpc.health.v1.Health/Check", "testroute0"),
+			urlrouter.NewRecord("/:service/:method", "testroute1"),
+			urlrouter.NewRecord("/:service/*rest", "testroute2"),
+		}, []lookup{
+			{"/grpc.health.v1.Health/Check", "testroute0", nil},
+			{"/pkg.Service/Method", "testroute1", []urlrouter.Param{{"service", "pkg.Service"}, {"method", "Method"}}},
+			{"/pkg.Service/a/b", "testroute2", []urlrouter.Param{{"service", "pkg.Service"}, {"rest", "a/b"}}},
+		}},
+		{urlrouter.Options{Syntax: dns}, []urlrouter.Record{
+			urlrouter.NewRecord("www.example.com", "testroute0"),
+			urlrouter.NewRecord(":host.example.com", "testroute1"),
+			urlrouter.NewRecord(":host.:zone.example.com", "testroute2"),
+		}, []lookup{
+			{"www.example.com", "testroute0", nil},
+			{"api.example.com", "testroute1", []urlrouter.Param{{"host", "api"}}},
+			{"api.eu.example.com", "testroute2", []urlrouter.Param{{"host", "api"}, {"zone", "eu"}}},
+			{"a/b.example.com", "testroute1", []urlrouter.Param{{"host", "a/b"}}},
+		}},
+	} {
+		r := urlrouter.WithOptions(router, testcase.opts).New()
+		if err := r.Build(testcase.records); err != nil {
+			t.Fatal(err)
+		}
+		for _, lookup := range testcase.lookups {
+			actual, params := r.Lookup(lookup.path)
+			if !reflect.DeepEqual(actual, lookup.value) {
+				t.Errorf("%q with %v expects %v, but %v", lookup.path, testcase.opts.Syntax, lookup.value, actual)
+			}
+			if !reflect.DeepEqual(params, lookup.params) {
+				t.Errorf("%q with %v expects %v, but %v", lookup.path, testcase.opts.Syntax, lookup.params, params)
+			}
+		}
+	}
+
+	r := urlrouter.WithOptions(router, urlrouter.Options{Syntax: mqtt}).New()
+	if err := r.Build([]urlrouter.Record{
+		urlrouter.NewRecord("sensors/+id/temp", "testroute0"),
+		urlrouter.NewRecord("sensors/#rest", "testroute1"),
+		urlrouter.NewRecord("sensors/:id", "testroute2"),
+		urlrouter.NewRecord("devices/+", "testroute3"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	actual := make(map[string]interface{})
+	if err := urlrouter.Walk(r, func(key string, value interface{}) error {
+		actual[key] = value
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"sensors/+id/temp": "testroute0",
+		"sensors/#rest":    "testroute1",
+		"sensors/:id":      "testroute2",
+		"devices/+0":       "testroute3",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+}
+
+func Test_URLRouter_Lookup_with_mid_path_wildcards(t *testing.T, router urlrouter.Router) {
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/repos/*path/blob", "testroute0"),
+		urlrouter.NewRecord("/repos/*path/tree/:ref", "testroute1"),
+		urlrouter.NewRecord("/repos/*path", "testroute2"),
+		urlrouter.NewRecord("/*group/-/issues/:id<int>", "testroute3"),
+		urlrouter.NewRecord("/*group/-/issues/new", "testroute4"),
+		urlrouter.NewRecord("/*dir/index.html", "testroute5"),
+		urlrouter.NewRecord("/files/*path.:ext", "testroute6"),
+		urlrouter.NewRecord("/c/*path/:id<int>/*rest", "testroute7"),
+		urlrouter.NewRecord("/g/*path/edit/:name", "testroute8"),
+		urlrouter.NewRecord("/g/*path/:action", "testroute9"),
+		urlrouter.NewRecord("/site(/**)/index.html", "testroute10"),
+		urlrouter.NewRecord("/raw/:kind/**", "testroute11"),
+	}
+	longest, shortest := urlrouter.Options{}, urlrouter.Options{ShortestWildcard: true}
+	for _, testcase := range []struct {
+		opts   urlrouter.Options
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+	}{
+		{longest, "/repos/a/b/blob", "testroute0", []urlrouter.Param{{"path", "a/b"}}},
+		{longest, "/repos/a/blob/b/blob", "testroute0", []urlrouter.Param{{"path", "a/blob/b"}}},
+		{longest, "/repos/a/tree/b/tree/dev", "testroute1", []urlrouter.Param{{"path", "a/tree/b"}, {"ref", "dev"}}},
+		{longest, "/repos/a/b", "testroute2", []urlrouter.Param{{"path", "a/b"}}},
+		{longest, "/repos/blob", "testroute2", []urlrouter.Param{{"path", "blob"}}},
+		{longest, "/repos/a/-/issues/1", "testroute2", []urlrouter.Param{{"path", "a/-/issues/1"}}},
+		{longest, "/gitlab-org/gitlab/-/issues/42", "testroute3", []urlrouter.Param{{"group", "gitlab-org/gitlab"}, {"id", "42"}}},
+		{longest, "/a/-/b/-/issues/7", "testroute3", []urlrouter.Param{{"group", "a/-/b"}, {"id", "7"}}},
+		{longest, "/c/a/-/issues/1", "testroute3", []urlrouter.Param{{"group", "c/a"}, {"id", "1"}}},
+		{longest, "/a/b/-/issues/new", "testroute4", []urlrouter.Param{{"group", "a/b"}}},
+		{longest, "/a/b/-/issues/x", nil, nil},
+		{longest, "/docs/v1/index.html", "testroute5", []urlrouter.Param{{"dir", "docs/v1"}}},
+		{longest, "/index.html", nil, nil},
+		{longest, "/files/a/b.tar.gz", "testroute6", []urlrouter.Param{{"path", "a/b.tar"}, {"ext", "gz"}}},
+		{longest, "/c/a/b/1/x/y", "testroute7", []urlrouter.Param{{"path", "a/b"}, {"id", "1"}, {"rest", "x/y"}}},
+		{longest, "/c/a/1/2/x", "testroute7", []urlrouter.Param{{"path", "a/1"}, {"id", "2"}, {"rest", "x"}}},
+		{longest, "/g/a/edit/b", "testroute9", []urlrouter.Param{{"path", "a/edit"}, {"action", "b"}}},
+		{longest, "/site/a/b/index.html", "testroute10", []urlrouter.Param{{"0", "a/b"}}},
+		{longest, "/site/index.html", "testroute10", nil},
+		{longest, "/raw/x/a/b", "testroute11", []urlrouter.Param{{"kind", "x"}, {"1", "a/b"}}},
+		{shortest, "/repos/a/blob/b/blob", "testroute0", []urlrouter.Param{{"path", "a/blob/b"}}},
+		{shortest, "/repos/a/b", "testroute2", []urlrouter.Param{{"path", "a/b"}}},
+		{shortest, "/a/-/b/-/issues/7", "testroute3", []urlrouter.Param{{"group", "a/-/b"}, {"id", "7"}}},
+		{shortest, "/files/a/b.tar.gz", "testroute6", []urlrouter.Param{{"path", "a/b.tar"}, {"ext", "gz"}}},
+		{shortest, "/c/a/b/1/x/y", "testroute7", []urlrouter.Param{{"path", "a/b"}, {"id", "1"}, {"rest", "x/y"}}},
+		{shortest, "/c/a/1/2/x", "testroute7", []urlrouter.Param{{"path", "a"}, {"id", "1"}, {"rest", "2/x"}}},
+		{shortest, "/g/a/edit/b", "testroute8", []urlrouter.Param{{"path", "a"}, {"name", "b"}}},
+		{urlrouter.Options{CaseInsensitive: true}, "/REPOS/A/B/BLOB", "testroute0", []urlrouter.Param{{"path", "A/B"}}},
+		{urlrouter.Options{CaseInsensitive: true, ShortestWildcard: true}, "/G/A/EDIT/B", "testroute8", []urlrouter.Param{{"path", "A"}, {"name", "B"}}},
+	} {
+		r := urlrouter.WithOptions(router, testcase.opts).New()
+		if err := r.Build(records); err != nil {
+			t.Fatal(err)
+		}
+		actual, params := r.Lookup(testcase.path)
+		if !reflect.DeepEqual(actual, testcase.value) {
+			t.Errorf("%q with %+v expects %v, but %v", testcase.path, testcase.opts, testcase.value, actual)
+		}
+		if !reflect.DeepEqual(params, testcase.params) {
+			t.Errorf("%q with %+v expects %v, but %v", testcase.path, testcase.opts, testcase.params, params)
+		}
+	}
+}
+
+func Test_URLRouter_Walk(t *testing.T, router urlrouter.Router) {
+	r := router.New()
+	if err := r.Build([]urlrouter.Record{
+		urlrouter.NewRecord("/", "testroute0"),
+		urlrouter.NewRecord("/path/to/route", "testroute1"),
+		urlrouter.NewRecord("/path/to/:param", "testroute2"),
+		urlrouter.NewRecord("/user/:id<int>/:name", "testroute3"),
+		urlrouter.NewRecord("/user/:name", "testroute4"),
+		urlrouter.NewRecord("/files/:name.:ext", "testroute5"),
+		urlrouter.NewRecord("/static/*filepath", "testroute6"),
+		urlrouter.NewRecord("/users/:id?", "testroute7"),
+		{Key: "/:lang/help", Value: "testroute8", Priority: 1},
+		urlrouter.NewRecord("/static/*filepath/edit", "testroute9"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	actual := make(map[string]interface{})
+	if err := urlrouter.Walk(r, func(key string, value interface{}) error {
+		if _, exists := actual[key]; exists {
+			t.Errorf("key %q is walked twice", key)
+		}
+		actual[key] = value
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"/":                      "testroute0",
+		"/path/to/route":         "testroute1",
+		"/path/to/:param":        "testroute2",
+		"/user/:id<int>/:name":   "testroute3",
+		"/user/:name":            "testroute4",
+		"/files/:name.:ext":      "testroute5",
+		"/static/*filepath":      "testroute6",
+		"/users/:id":             "testroute7",
+		"/users":                 "testroute7",
+		"/:lang/help":            "testroute8",
+		"/static/*filepath/edit": "testroute9",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expect %v, but %v", expected, actual)
+	}
+
+	errStop := errors.New("stop")
+	n := 0
+	if err := urlrouter.Walk(r, func(key string, value interface{}) error {
+		n++
+		return errStop
+	}); err != errStop {
+		t.Errorf("Expect %v, but %v", errStop, err)
+	}
+	if n != 1 {
+		t.Errorf("Expect walk to be stopped by the first error, but called %d times", n)
+	}
+}
+
+func Test_URLRouter_Dump(t *testing.T, router urlrouter.Router) {
+	r := router.New()
+	records := []urlrouter.Record{
+		urlrouter.NewRecord("/path/to/route", "testroute0"),
+		urlrouter.NewRecord("/user/:id<int>", "testroute1"),
+		urlrouter.NewRecord("/user/:name", "testroute2"),
+		urlrouter.NewRecord("/static/*filepath", "testroute3"),
+	}
+	if err := r.Build(records); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []urlrouter.DumpFormat{urlrouter.DumpText, urlrouter.DumpDOT} {
+		var buf bytes.Buffer
+		if err := urlrouter.Dump(&buf, r, format); err != nil {
+			t.Errorf("%v: unexpected error: %v", format, err)
+			continue
+		}
+		out := buf.String()
+		for _, record := range records {
+			if !strings.Contains(out, record.Value.(string)) {
+				t.Errorf("%v: Expect %q in the dump, but not\n%s", format, record.Value, out)
+			}
+		}
+		if format == urlrouter.DumpDOT && (!strings.HasPrefix(out, "digraph ") || !strings.HasSuffix(out, "}\n")) {
+			t.Errorf("%v: Expect a digraph, but %q", format, out)
+		}
+	}
+	if err := urlrouter.Dump(&bytes.Buffer{}, r, urlrouter.DumpFormat(-1)); err == nil {
+		t.Errorf("Expect error by an unsupported format, but nil")
+	}
+}
+
+func Test_HostRouter_Lookup(t *testing.T, router urlrouter.Router) {
+	r := urlrouter.NewHostRouter(router)
+	if err := r.Build([]urlrouter.HostRecord{
+		{"example.com", urlrouter.NewRecord("/", "testroute0")},
+		{"example.com", urlrouter.NewRecord("/user/:id", "testroute1")},
+		{"api.example.com", urlrouter.NewRecord("/user/:id", "testroute2")},
+		{":tenant.example.com", urlrouter.NewRecord("/", "testroute3")},
+		{":tenant.example.com", urlrouter.NewRecord("/user/:id", "testroute4")},
+		{":tenant.:region.example.org", urlrouter.NewRecord("/*path", "testroute5")},
+		{"", urlrouter.NewRecord("/health", "testroute6")},
+		{"", urlrouter.NewRecord("/user/:id", "testroute7")},
+		{"api.example.com", urlrouter.NewRecord("/status", "testroute8")},
+		{":tenant.example.com", urlrouter.NewRecord("/dashboard", "testroute9")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		host   string
+		path   string
+		value  interface{}
+		params []urlrouter.Param
+	}{
+		{"example.com", "/", "testroute0", nil},
+		{"Example.COM:8080", "/", "testroute0", nil},
+		{"example.com", "/user/1", "testroute1", []urlrouter.Param{{"id", "1"}}},
+		{"api.example.com", "/user/1", "testroute2", []urlrouter.Param{{"id", "1"}}},
+		{"acme.example.com", "/", "testroute3", []urlrouter.Param{{"tenant", "acme"}}},
+		{"acme.example.com", "/user/1", "testroute4", []urlrouter.Param{{"tenant", "acme"}, {"id", "1"}}},
+		{"acme.eu.example.org", "/a/b", "testroute5", []urlrouter.Param{{"tenant", "acme"}, {"region", "eu"}, {"path", "a/b"}}},
+		{"acme.example.com", "/health", "testroute6", nil},
+		{"example.net", "/user/1", "testroute7", []urlrouter.Param{{"id", "1"}}},
+		{"example.net", "/", nil, nil},
+		{"a.b.example.com", "/", nil, nil},
+		{"api.example.com", "/status", "testroute8", nil},
+		// the other host patterns that match are tried if the best host pattern has no route of the path.
+		{"api.example.com", "/dashboard", "testroute9", []urlrouter.Param{{"tenant", "api"}}},
+		{"api.example.com", "/", "testroute3", []urlrouter.Param{{"tenant", "api"}}},
+		{"api.example.com", "/health", "testroute6", nil},
+	} {
+		actual, params := r.Lookup(testcase.host, testcase.path)
+		if !reflect.DeepEqual(actual, testcase.value) {
+			t.Errorf("%q %q expects %v, but %v", testcase.host, testcase.path, testcase.value, actual)
+		}
+		if !reflect.DeepEqual(params, testcase.params) {
+			t.Errorf("%q %q expects %v, but %v", testcase.host, testcase.path, testcase.params, params)
+		}
+	}
+}
+
+func Test_URLRouter_LookupInto(t *testing.T, router urlrouter.Router) {
+	r := router.New()
+	if err := r.Build(routes()); err != nil {
+		t.Fatal(err)
+	}
+	lookuper, ok := r.(urlrouter.BufferedLookuper)
+	if !ok {
+		t.Fatalf("%T doesn't implement urlrouter.BufferedLookuper", r)
+	}
+	dst := make([]urlrouter.Param, 0, 8)
+	for _, path := range []string{
+		"/", "/path/to/route", "/path/to/hoge", "/path/to/wildcard/some/params", "/path/to/p1/sep/p2",
+		"/2014/01/06", "/a/to/b/p1/some/wildcard/params", "/missing",
+	} {
+		expected, expectedParams := r.Lookup(path)
+		actual, params := lookuper.LookupInto(path, dst)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%q expects %v, but %v", path, expected, actual)
+		}
+		if len(params) != len(expectedParams) || len(params) > 0 && !reflect.DeepEqual(params, expectedParams) {
+			t.Errorf("%q expects %v, but %v", path, expectedParams, params)
+		}
+		if len(params) > 0 && &params[0] != &dst[:1][0] {
+			t.Errorf("%q: params doesn't use the given buffer", path)
+		}
+	}
+}
+
+// Test_URLRouter_LookupInto_allocs tests that LookupInto doesn't allocate for paths.
+func Test_URLRouter_LookupInto_allocs(t *testing.T, router urlrouter.Router, paths ...string) {
+	r := router.New()
+	if err := r.Build(routes()); err != nil {
+		t.Fatal(err)
+	}
+	lookuper := r.(urlrouter.BufferedLookuper)
+	dst := make([]urlrouter.Param, 0, 8)
+	for _, path := range paths {
+		allocs := testing.AllocsPerRun(100, func() {
+			if data, _ := lookuper.LookupInto(path, dst); data == nil {
+				t.Fatalf("%q isn't found", path)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("%q: expect 0 allocs/op, but %v", path, allocs)
+		}
+	}
+}
+
 func Test_URLRouter_Lookup_with_many_routes(t *testing.T, router urlrouter.Router) {
 	n := 1000
 	rand.Seed(time.Now().UnixNano())
 	records := make([]urlrouter.Record, n)
 	for i := 0; i < n; i++ {
-		records[i] = urlrouter.Record{"/" + RandomString(rand.Intn(50)+10), fmt.Sprintf("route%d", i)}
+		records[i] = urlrouter.NewRecord("/"+RandomString(rand.Intn(50)+10), fmt.Sprintf("route%d", i))
 	}
 	r := router.New()
 	if err := r.Build(records); err != nil {
@@ -114,11 +788,58 @@ func Test_URLRouter_Build(t *testing.T, router urlrouter.Router) {
 	// test for duplicate name of path parameters.
 	func() {
 		r := router.New()
-		if err := r.Build([]urlrouter.Record{
-			{"/:user/:id/:id", "testroute0"},
-			{"/:user/:user/:id", "testroute0"},
-		}); err == nil {
+		err := r.Build([]urlrouter.Record{
+			urlrouter.NewRecord("/:user/:id/:id", "testroute0"),
+			urlrouter.NewRecord("/:user/:user/:id", "testroute0"),
+		})
+		if err == nil {
 			t.Errorf("no error returned by duplicate name of path parameters")
+			return
+		}
+		var errs urlrouter.Errors
+		errors.As(err, &errs)
+		var dups []urlrouter.DuplicateParamError
+		for _, err := range errs {
+			var dup *urlrouter.DuplicateParamError
+			if errors.As(err, &dup) {
+				dups = append(dups, *dup)
+			}
+		}
+		var actual interface{} = dups
+		var expected interface{} = []urlrouter.DuplicateParamError{
+			{Key: "/:user/:id/:id", Index: 0, Name: "id", Offset: 11},
+			{Key: "/:user/:user/:id", Index: 1, Name: "user", Offset: 7},
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expect %v, but %v", expected, actual)
 		}
 	}()
+
+	// test for invalid constraints of path parameters.
+	for _, key := range []string{"/user/:id<unknown>", "/user/:id<int", "/user/:id([0-9]+", "/user/:id([0-9+)"} {
+		r := router.New()
+		if err := r.Build([]urlrouter.Record{urlrouter.NewRecord(key, "testroute0")}); err == nil {
+			t.Errorf("no error returned by invalid constraint %q", key)
+		}
+	}
+
+	// test for empty names of path parameters.
+	for _, key := range []string{"/user/:<int>", "/user/:-x", "/static/a**", "/static/**a"} {
+		r := router.New()
+		err := r.Build([]urlrouter.Record{urlrouter.NewRecord(key, "testroute0")})
+		var syntaxErr *urlrouter.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("no *urlrouter.SyntaxError returned by empty name of path parameter %q: %v", key, err)
+		}
+	}
+
+	// test for names of path parameters that are followed by a literal, they were names such as "user-id" previously.
+	for _, key := range []string{"/users/:user-id", "/users/:user-id/edit", "/users/:id~x.json", "/files/*path-x"} {
+		r := router.New()
+		err := r.Build([]urlrouter.Record{urlrouter.NewRecord(key, "testroute0")})
+		var syntaxErr *urlrouter.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("no *urlrouter.SyntaxError returned by name of path parameter followed by a literal %q: %v", key, err)
+		}
+	}
 }
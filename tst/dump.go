@@ -0,0 +1,95 @@
+package tst
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/naoina/kocha-urlrouter"
+)
+
+// Dump implements the urlrouter.Dumper.
+// It writes the nodes of TST. In the text format, the nodes of mid and path parameters are indented deeper than
+// their parent, and the nodes of left and right are indented at the same depth as their parent.
+func (tst *TST) Dump(w io.Writer, format urlrouter.DumpFormat) error {
+	d := &dumper{w: w, format: format, syntax: tst.opts.SyntaxOrDefault()}
+	switch format {
+	case urlrouter.DumpText:
+		d.dump(tst.root, "root", -1, "", 0)
+	case urlrouter.DumpDOT:
+		d.printf("digraph tst {\n\tnode [shape=box];\n")
+		d.dump(tst.root, "root", -1, "", 0)
+		d.printf("}\n")
+	default:
+		return fmt.Errorf("unsupported dump format %v", format)
+	}
+	return d.err
+}
+
+// dumper writes the nodes of TST.
+type dumper struct {
+	w      io.Writer
+	format urlrouter.DumpFormat
+	syntax *urlrouter.Syntax
+
+	// Number of the nodes that have been written. It is used as an ID of the node in the DOT format.
+	n int
+
+	// The first error of writing.
+	err error
+}
+
+func (d *dumper) printf(format string, a ...interface{}) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, a...)
+	}
+}
+
+// dump writes nd and its descendants.
+// parent is the ID of the parent node, and edge is the kind of the edge from the parent node.
+func (d *dumper) dump(nd *node, label string, parent int, edge string, depth int) {
+	id := d.n
+	d.n++
+	if nd.isLeaf {
+		label += fmt.Sprintf(" => %v %v", nd.data, nd.paramNames)
+		if nd.priority != 0 {
+			label += fmt.Sprintf(" (priority %d)", nd.priority)
+		}
+	}
+	switch d.format {
+	case urlrouter.DumpText:
+		if edge != "" {
+			label = edge + " " + label
+		}
+		d.printf("%s%s\n", strings.Repeat("  ", depth), label)
+	case urlrouter.DumpDOT:
+		peripheries := 1
+		if nd.isLeaf {
+			peripheries = 2
+		}
+		d.printf("\tn%d [label=%s, peripheries=%d];\n", id, strconv.Quote(label), peripheries)
+		if parent >= 0 {
+			d.printf("\tn%d -> n%d [label=%s];\n", parent, id, strconv.Quote(edge))
+		}
+	}
+	if nd.left != nil {
+		d.dump(nd.left, fmt.Sprintf("%q", nd.left.c), id, "left", depth)
+	}
+	if nd.mid != nil {
+		d.dump(nd.mid, fmt.Sprintf("%q", nd.mid.c), id, "mid", depth+1)
+	}
+	if nd.right != nil {
+		d.dump(nd.right, fmt.Sprintf("%q", nd.right.c), id, "right", depth)
+	}
+	for _, paramNode := range nd.paramNodes {
+		label := string(d.syntax.ParamChar())
+		if paramNode.constraint != nil {
+			label += paramNode.constraint.String()
+		}
+		d.dump(paramNode, label, id, "param", depth+1)
+	}
+	if nd.wildcardNode != nil {
+		d.dump(nd.wildcardNode, string(d.syntax.WildcardChar()), id, "wildcard", depth+1)
+	}
+}
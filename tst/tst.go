@@ -2,14 +2,20 @@
 package tst
 
 import (
-	"fmt"
+	"sort"
 
 	"github.com/naoina/kocha-urlrouter"
+	"github.com/naoina/kocha-urlrouter/match"
 )
 
 // TST represents a URLRouter by Ternary Search Tree.
 type TST struct {
 	root *node
+
+	// Maximum priority of the records.
+	maxPriority int
+
+	opts urlrouter.Options
 }
 
 // New returns a new TST.
@@ -19,40 +25,88 @@ func New() *TST {
 
 // Lookup returns result data of lookup from TST routing table by given path.
 func (tst *TST) Lookup(path string) (data interface{}, params []urlrouter.Param) {
-	nd, values := tst.root.Find(path, []string{})
-	if nd == nil || !nd.isLeaf {
-		return nil, nil
-	}
-	if len(values) > 0 {
-		params = make([]urlrouter.Param, len(values))
-		for i, v := range values {
-			params[i] = urlrouter.Param{Name: nd.paramNames[i], Value: v}
-		}
+	return tst.LookupInto(path, nil)
+}
+
+// LookupInto is the same as Lookup, but path parameters are appended to dst[:0].
+// It doesn't allocate if the capacity of dst is enough to hold path parameters and
+// all records that match path have the maximum Priority in the routing table.
+func (tst *TST) LookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	data, params, _ = urlrouter.LookupFixed(path, tst.opts.TrailingSlash, dst, tst.lookupInto)
+	return data, params
+}
+
+// LookupTrailingSlash implements the urlrouter.TrailingSlashLookuper.
+func (tst *TST) LookupTrailingSlash(path string) (data interface{}, params []urlrouter.Param, fixed string) {
+	return urlrouter.LookupFixed(path, tst.opts.TrailingSlash, nil, tst.lookupInto)
+}
+
+// lookupInto looks up path as it is.
+func (tst *TST) lookupInto(path string, dst []urlrouter.Param) (data interface{}, params []urlrouter.Param) {
+	if tst.opts.Normalize {
+		path = urlrouter.NormalizePath(path)
+	}
+	m := match.New[*node](tst.maxPriority, tst.opts)
+	tst.root.Find(path, dst[:0], &m)
+	nd, matched, found := m.Result()
+	if !found {
+		return nil, dst[:0]
+	}
+	params = append(dst[:0], matched...)
+	for i := range params {
+		params[i].Name = nd.paramNames[i]
 	}
 	return nd.data, params
 }
 
+// SetOptions implements the urlrouter.Configurable.
+func (tst *TST) SetOptions(opts urlrouter.Options) {
+	tst.opts = opts
+}
+
 // Build builds TST routing table from records.
+// Optional segments of keys are expanded by urlrouter.ExpandRecords.
 func (tst *TST) Build(records []urlrouter.Record) error {
+	s := tst.opts.SyntaxOrDefault()
+	if err := tst.opts.CheckDuplicateKeys(records); err != nil {
+		return err
+	}
+	records, err := s.ExpandRecords(records)
+	if err != nil {
+		return err
+	}
+	records = urlrouter.NormalizeRecords(records, tst.opts)
+	tst.root, tst.maxPriority = &node{}, urlrouter.MaxPriority(records)
 	for _, record := range records {
-		if err := tst.root.Add(record.Key, record.Value); err != nil {
+		if err := tst.root.Add(s, record.Key, record.Value, record.Priority); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// Walk implements the urlrouter.Walker.
+// The routes are walked in the order of the tree, that is, the static routes are walked in the order of keys,
+// and the routes of path parameters follow the routes that share the prefix of them.
+func (tst *TST) Walk(fn func(key string, value interface{}) error) error {
+	return tst.root.walk(tst.opts.SyntaxOrDefault(), nil, nil, nil, fn)
+}
+
 // node represents a node of TST.
 type node struct {
 	c            byte
 	data         interface{}
+	priority     int
 	left         *node
 	mid          *node
 	right        *node
-	paramNode    *node
+	paramNodes   []*node
 	wildcardNode *node
 	paramNames   []string
 	isLeaf       bool
+
+	// Constraint of the path parameter that leads to this node.
+	constraint *urlrouter.Constraint
 }
 
 type nodeIndex struct {
@@ -60,32 +114,102 @@ type nodeIndex struct {
 	idx int
 }
 
-func (nd *node) Find(path string, params []string) (*node, []string) {
-	var nodes []nodeIndex
-	for i := 0; i < len(path); i++ {
-		if nd = nd.mid.find(path[i]); nd == nil {
-			goto PARAMED_ROUTE
+// Find looks up the nodes that match path, and passes them to m with params that values of path parameters
+// are appended. Names of params aren't set. It reports whether m stopped the lookup.
+func (nd *node) Find(path string, params []urlrouter.Param, m *match.Matcher[*node]) bool {
+	var buf [8]nodeIndex
+	nodes := buf[:0]
+	if len(nd.paramNodes) > 0 || nd.wildcardNode != nil {
+		nodes = append(nodes, nodeIndex{nd, 0})
+	}
+	n := nd
+	for i := 0; i < len(path) && n != nil; i++ {
+		c := path[i]
+		if m.CaseInsensitive() {
+			c = urlrouter.FoldByte(c)
 		}
-		if nd.paramNode != nil || nd.wildcardNode != nil {
-			nodes = append(nodes, nodeIndex{nd, i + 1})
+		if n = n.mid.find(c); n != nil && (len(n.paramNodes) > 0 || n.wildcardNode != nil) {
+			nodes = append(nodes, nodeIndex{n, i + 1})
 		}
 	}
-	return nd, params
-PARAMED_ROUTE:
+	if n != nil && n.isLeaf && m.Match(n, n.priority, params) {
+		return true
+	}
 	for i := len(nodes) - 1; i >= 0; i-- {
 		nd, idx := nodes[i].nd, nodes[i].idx
-		if nd.paramNode != nil {
-			i := urlrouter.NextSeparator(path, idx)
-			remaining, params := path[i:], append(params, path[idx:i])
-			if nd, params := nd.paramNode.Find(remaining, params); nd != nil {
-				return nd, params
+		for _, paramNode := range nd.paramNodes {
+			var buf [8]int
+			for _, end := range m.Syntax().ParamEnds(buf[:0], path, idx, m.CaseInsensitive(), paramNode.hasChild) {
+				value := path[idx:end]
+				if paramNode.constraint != nil && !paramNode.constraint.Match(value) {
+					continue
+				}
+				if paramNode.Find(path[end:], append(params, urlrouter.Param{Value: value}), m) {
+					return true
+				}
 			}
 		}
-		if nd.wildcardNode != nil {
-			return nd.wildcardNode, append(params, path[idx:])
+		if wildcardNode := nd.wildcardNode; wildcardNode != nil && idx < len(path) {
+			var buf [8]int
+			for _, end := range urlrouter.WildcardEnds(buf[:0], path, idx, m.CaseInsensitive(), m.ShortestWildcard(), wildcardNode.hasChild) {
+				if wildcardNode.Find(path[end:], append(params, urlrouter.Param{Value: path[idx:end]}), m) {
+					return true
+				}
+			}
+			// the key that ends with the wildcard path parameter is the last resort.
+			if wildcardNode.isLeaf && m.Match(wildcardNode, wildcardNode.priority, append(params, urlrouter.Param{Value: path[idx:]})) {
+				return true
+			}
 		}
 	}
-	return nil, nil
+	return false
+}
+
+// walk calls fn for the routes of nd and its descendants.
+// prefix is the static part of the key after the last path parameter, and statics and params are the parts of
+// the key before it. (see urlrouter.JoinKey)
+func (nd *node) walk(s *urlrouter.Syntax, prefix []byte, statics, params []string, fn func(key string, value interface{}) error) error {
+	if nd.isLeaf {
+		if err := fn(urlrouter.JoinKey(append(statics[:len(statics):len(statics)], string(prefix)), params, nd.paramNames), nd.data); err != nil {
+			return err
+		}
+	}
+	if err := nd.mid.walkSiblings(s, prefix, statics, params, fn); err != nil {
+		return err
+	}
+	statics = append(statics[:len(statics):len(statics)], string(prefix))
+	for _, paramNode := range nd.paramNodes {
+		param := string(s.ParamChar())
+		if paramNode.constraint != nil {
+			param += paramNode.constraint.String()
+		}
+		if err := paramNode.walk(s, nil, statics, append(params[:len(params):len(params)], param), fn); err != nil {
+			return err
+		}
+	}
+	if nd.wildcardNode != nil {
+		return nd.wildcardNode.walk(s, nil, statics, append(params[:len(params):len(params)], string(s.WildcardChar())), fn)
+	}
+	return nil
+}
+
+// walkSiblings calls walk for nd and the nodes of left and right of nd in the order of the characters.
+func (nd *node) walkSiblings(s *urlrouter.Syntax, prefix []byte, statics, params []string, fn func(key string, value interface{}) error) error {
+	if nd == nil {
+		return nil
+	}
+	if err := nd.left.walkSiblings(s, prefix, statics, params, fn); err != nil {
+		return err
+	}
+	if err := nd.walk(s, append(prefix[:len(prefix):len(prefix)], nd.c), statics, params, fn); err != nil {
+		return err
+	}
+	return nd.right.walkSiblings(s, prefix, statics, params, fn)
+}
+
+// hasChild returns whether nd has the child node of c.
+func (nd *node) hasChild(c byte) bool {
+	return nd.mid.find(c) != nil
 }
 
 func (nd *node) find(c byte) *node {
@@ -102,23 +226,31 @@ func (nd *node) find(c byte) *node {
 	return nil
 }
 
-func (nd *node) Add(path string, data interface{}) error {
+func (nd *node) Add(s *urlrouter.Syntax, path string, data interface{}, priority int) error {
 	var paramNames []string
 	for i := 0; i < len(path); i++ {
-		switch c, remaining := path[i], path[i+1:]; c {
-		case urlrouter.ParamCharacter:
-			next := urlrouter.NextSeparator(remaining, 0)
-			paramNames = append(paramNames, remaining[:next])
-			if nd.paramNode == nil {
-				nd.paramNode = &node{}
+		switch c := path[i]; c {
+		case s.ParamChar():
+			name, constraint, next, err := s.ParseParam(path, i)
+			if err != nil {
+				return err
+			}
+			paramNames = append(paramNames, name)
+			if nd, err = nd.paramNode(constraint); err != nil {
+				return err
+			}
+			i = next - 1
+		case s.WildcardChar():
+			name, _, next, err := s.ParseParam(path, i)
+			if err != nil {
+				return err
+			}
+			paramNames = append(paramNames, name)
+			if nd.wildcardNode == nil {
+				nd.wildcardNode = &node{}
 			}
-			i += next
-			nd = nd.paramNode
-		case urlrouter.WildcardCharacter:
-			paramNames = append(paramNames, remaining[:len(remaining)])
-			nd.wildcardNode = &node{}
 			nd = nd.wildcardNode
-			i = len(path)
+			i = next - 1
 		default:
 			n := nd.mid.find(c)
 			if n == nil {
@@ -132,17 +264,35 @@ func (nd *node) Add(path string, data interface{}) error {
 			nd = n
 		}
 	}
-	if len(paramNames) > 0 {
-		dups := make(map[string]bool)
-		for _, name := range paramNames {
-			if dups[name] {
-				return fmt.Errorf("path parameter `%v` is duplicated in the key '%v'", name, path)
-			}
-			dups[name] = true
+	nd.data, nd.priority, nd.paramNames, nd.isLeaf = data, priority, paramNames, true
+	return nil
+}
+
+// paramNode returns the child node of path parameter that has constraint.
+// A new node will be created if it doesn't exist.
+// The nodes that have a constraint are ordered by the constraint, and the node that has no constraint is the last.
+func (nd *node) paramNode(constraint string) (*node, error) {
+	for _, n := range nd.paramNodes {
+		if n.constraint == nil && constraint == "" || n.constraint != nil && n.constraint.String() == constraint {
+			return n, nil
 		}
 	}
-	nd.data, nd.paramNames, nd.isLeaf = data, paramNames, true
-	return nil
+	n := &node{}
+	if constraint != "" {
+		c, err := urlrouter.NewConstraint(constraint)
+		if err != nil {
+			return nil, err
+		}
+		n.constraint = c
+	}
+	i := sort.Search(len(nd.paramNodes), func(i int) bool {
+		c := nd.paramNodes[i].constraint
+		return c == nil || n.constraint != nil && c.String() > n.constraint.String()
+	})
+	nd.paramNodes = append(nd.paramNodes, nil)
+	copy(nd.paramNodes[i+1:], nd.paramNodes[i:])
+	nd.paramNodes[i] = n
+	return n, nil
 }
 
 // add adds a node to leaf.
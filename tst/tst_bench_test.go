@@ -18,6 +18,14 @@ func Benchmark_TST_Lookup_700(b *testing.B) {
 	testutil.Benchmark_URLRouter_Lookup(b, New(), 700)
 }
 
+func Benchmark_TST_LookupInto_static(b *testing.B) {
+	testutil.Benchmark_URLRouter_LookupInto(b, New(), "/path/to/route")
+}
+
+func Benchmark_TST_LookupInto_param(b *testing.B) {
+	testutil.Benchmark_URLRouter_LookupInto(b, New(), "/path/to/p1/sep/p2")
+}
+
 func Benchmark_TST_Build_100(b *testing.B) {
 	testutil.Benchmark_URLRouter_Build(b, &TSTRouter{}, 100)
 }
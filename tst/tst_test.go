@@ -21,6 +21,55 @@ func Test_TST_Lookup(t *testing.T) {
 	testutil.Test_URLRouter_Lookup(t, &TSTRouter{})
 }
 
+func Test_TST_Lookup_with_constraints(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_constraints(t, &TSTRouter{})
+}
+
+func Test_TST_Lookup_with_params_in_segment(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_params_in_segment(t, &TSTRouter{})
+}
+
+func Test_TST_Lookup_with_precedence(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_precedence(t, &TSTRouter{})
+}
+
+func Test_TST_Lookup_with_optional_segments(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_optional_segments(t, &TSTRouter{})
+}
+
+func Test_TST_Lookup_with_options(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_options(t, &TSTRouter{})
+}
+
+func Test_TST_Lookup_with_trailing_slash(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_trailing_slash(t, &TSTRouter{})
+}
+
+func Test_TST_Lookup_with_syntax(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_syntax(t, &TSTRouter{})
+}
+
+func Test_TST_Lookup_with_mid_path_wildcards(t *testing.T) {
+	testutil.Test_URLRouter_Lookup_with_mid_path_wildcards(t, &TSTRouter{})
+}
+
+func Test_TST_Walk(t *testing.T) {
+	testutil.Test_URLRouter_Walk(t, &TSTRouter{})
+}
+
+func Test_TST_Dump(t *testing.T) {
+	testutil.Test_URLRouter_Dump(t, &TSTRouter{})
+}
+
+func Test_TST_HostRouter_Lookup(t *testing.T) {
+	testutil.Test_HostRouter_Lookup(t, &TSTRouter{})
+}
+
+func Test_TST_LookupInto(t *testing.T) {
+	testutil.Test_URLRouter_LookupInto(t, &TSTRouter{})
+	testutil.Test_URLRouter_LookupInto_allocs(t, &TSTRouter{}, "/path/to/route", "/path/to/p1/sep/p2", "/a/to/b/p1/some/wildcard/params")
+}
+
 func Test_TST_Lookup_with_many_routes(t *testing.T) {
 	testutil.Test_URLRouter_Lookup_with_many_routes(t, &TSTRouter{})
 }
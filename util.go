@@ -1,27 +1,299 @@
 package urlrouter
 
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
 // NextSeparator returns an index of next separator in path.
+// It is the same as DefaultSyntax.NextSeparator.
 func NextSeparator(path string, start int) int {
-	for start < len(path) && path[start] != '/' && path[start] != '.' {
-		start++
-	}
-	return start
+	return DefaultSyntax.NextSeparator(path, start)
 }
 
-// isMetaChar returns whether the meta character.
+// IsSeparator returns whether c is a separator of the segments of path.
+// It is the same as DefaultSyntax.IsSeparator.
+func IsSeparator(c byte) bool {
+	return DefaultSyntax.IsSeparator(c)
+}
+
+// IsMetaChar returns whether the meta character.
+// It is the same as DefaultSyntax.IsMetaChar.
 func IsMetaChar(c byte) bool {
-	return c == ParamCharacter || c == WildcardCharacter
+	return DefaultSyntax.IsMetaChar(c)
 }
 
 // ParamNames returns parameter names in given path.
-// It returns names which meta character is prefixed.
+// It is the same as DefaultSyntax.ParamNames.
 func ParamNames(path string) (names []string) {
+	return DefaultSyntax.ParamNames(path)
+}
+
+// ParamNames returns parameter names in given path.
+// It returns names which meta character is prefixed.
+// Constraints of path parameters aren't included in names, and anonymous path parameters are named by their
+// positions as ExpandOptional does.
+func (s *Syntax) ParamNames(path string) (names []string) {
+	path = s.nameAnonymous(path)
 	for i := 0; i < len(path); i++ {
-		if IsMetaChar(path[i]) {
-			next := NextSeparator(path, i+1)
-			names = append(names, path[i:next])
+		if s.IsMetaChar(path[i]) {
+			next := s.NextSeparator(path, i+1)
+			name := path[i+1 : next]
+			if path[i] == s.paramChar {
+				if n, _, end, err := s.ParseParam(path, i); err == nil {
+					name, next = n, end
+				}
+			}
+			names = append(names, path[i:i+1]+name)
 			i = next
 		}
 	}
 	return names
 }
+
+// isNameChar returns whether c can be used in a name of path parameter.
+func isNameChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_'
+}
+
+// ParseParam parses a path parameter that starts with the meta character at path[start].
+// It is the same as DefaultSyntax.ParseParam.
+func ParseParam(path string, start int) (name, constraint string, end int, err error) {
+	return DefaultSyntax.ParseParam(path, start)
+}
+
+// ParseParam parses a path parameter that starts with the meta character at path[start].
+// It returns the name and the constraint of the parameter, and an index of the next of the end of the parameter.
+// A name consists of ASCII letters, digits and '_'. The constraint is a string such as "<int>" or "([0-9]+)" that
+// follows the name, or empty if the parameter has no constraint.
+// A wildcard path parameter can't have a constraint.
+// A path parameter can be followed by a separator, a literal such as "-" of "/:from-:to", or the end of path.
+// It returns a *SyntaxError if the name is empty, or if the parameter is followed by another path parameter or
+// a non-ASCII character.
+// It also returns a *SyntaxError if a literal directly follows the name and no path parameter follows the literal
+// in the segment, e.g. "/:user-id", because such a key had the name "user-id" in the previous versions.
+// A constraint ends the name, e.g. "/:id<int>px" has the name "id" followed by the literal "px".
+func (s *Syntax) ParseParam(path string, start int) (name, constraint string, end int, err error) {
+	i := start + 1
+	for i < len(path) && isNameChar(path[i]) {
+		i++
+	}
+	name, end = path[start+1:i], i
+	wildcard := path[start] == s.wildcardChar
+	if name == "" {
+		if wildcard && i < len(path) && path[i] == s.wildcardChar {
+			return "", "", -1, syntaxError(path, start, "anonymous wildcard path parameter `%c%c` must occupy a segment", s.wildcardChar, s.wildcardChar)
+		}
+		if wildcard {
+			return "", "", -1, syntaxError(path, start, "name of wildcard path parameter is empty")
+		}
+		return "", "", -1, syntaxError(path, start, "name of path parameter is empty")
+	}
+	if i < len(path) && (path[i] == '<' || path[i] == '(') {
+		if wildcard {
+			return "", "", -1, syntaxError(path, i, "wildcard path parameter `%v` can't have a constraint", name)
+		}
+		if end, err = constraintEnd(path, i); err != nil {
+			return "", "", -1, err
+		}
+		constraint = path[i:end]
+	}
+	if end < len(path) {
+		switch c := path[end]; {
+		case s.IsMetaChar(c):
+			return "", "", -1, syntaxError(path, end, "path parameter `%v` must be followed by a separator or a literal", name)
+		case c >= utf8.RuneSelf:
+			return "", "", -1, syntaxError(path, end, "path parameter `%v` must be followed by an ASCII character", name)
+		case constraint == "" && !s.IsSeparator(c):
+			sep := s.NextSeparator(path, end)
+			if !s.HasMetaChar(path[end:sep]) {
+				return "", "", -1, syntaxError(path, end, "name of path parameter `%v` can't be followed by the literal `%v`; a name consists of ASCII letters, digits and '_'", name, path[end:sep])
+			}
+		}
+	}
+	return name, constraint, end, nil
+}
+
+// nameAnonymous returns key that the anonymous path parameters are named by their positions.
+// An anonymous path parameter is the meta character that isn't followed by a name such as "sensors/+/temp" or
+// "/static/*", or the doubled wildcard character that occupies a segment such as "/**/index.html".
+// The position is an index of the path parameter in key, e.g. "/:dir/**/index.html" is named "/:dir/*1/index.html".
+// key is returned as is if it has no anonymous path parameters.
+func (s *Syntax) nameAnonymous(key string) string {
+	var buf []byte
+	last, n := 0, 0
+	for i := 0; i < len(key); i++ {
+		if !s.IsMetaChar(key[i]) {
+			continue
+		}
+		if size := s.anonymousParamLen(key, i); size > 0 {
+			buf = append(buf, key[last:i+1]...)
+			buf = strconv.AppendInt(buf, int64(n), 10)
+			last = i + size
+			i = last - 1
+		} else if _, _, end, err := s.ParseParam(key, i); err == nil {
+			i = end - 1
+		}
+		n++
+	}
+	if buf == nil {
+		return key
+	}
+	return string(append(buf, key[last:]...))
+}
+
+// anonymousParamLen returns the length of the anonymous path parameter at key[i], or 0 if it isn't anonymous.
+func (s *Syntax) anonymousParamLen(key string, i int) int {
+	end := i + 1
+	if key[i] == s.wildcardChar && end < len(key) && key[end] == s.wildcardChar && (i == 0 || s.IsSeparator(key[i-1])) {
+		end++
+	}
+	if end < len(key) && !s.IsSeparator(key[end]) && key[end] != ')' && key[end] != OptionalCharacter {
+		return 0
+	}
+	return end - i
+}
+
+// ParamEnds appends the ends of the values of a path parameter that starts at path[start] to dst.
+// It is the same as DefaultSyntax.ParamEnds.
+func ParamEnds(dst []int, path string, start int, caseInsensitive bool, isDelimiter func(c byte) bool) []int {
+	return DefaultSyntax.ParamEnds(dst, path, start, caseInsensitive, isDelimiter)
+}
+
+// ParamEnds appends the ends of the values of a path parameter that starts at path[start] to dst in the order of
+// the precedence, and returns it.
+// isDelimiter reports whether c is the first character of a literal that follows the path parameter in a key of
+// the routing table. The value of a path parameter that is followed by a separator or the end of the key is the
+// rest of the segment, and the value of a path parameter that is followed by a literal ends at the first occurrence
+// of the first character of the literal. The values are never empty.
+// If caseInsensitive is true, the characters of path are folded by FoldByte before being passed to isDelimiter.
+func (s *Syntax) ParamEnds(dst []int, path string, start int, caseInsensitive bool, isDelimiter func(c byte) bool) []int {
+	sep := s.NextSeparator(path, start)
+	if sep == start {
+		return dst
+	}
+	n := len(dst)
+	dst = append(dst, sep)
+	var seen [4]uint64
+	for i := start; i < sep; i++ {
+		c := path[i]
+		if caseInsensitive {
+			c = FoldByte(c)
+		}
+		if seen[c/64]&(1<<(c%64)) != 0 {
+			continue
+		}
+		seen[c/64] |= 1 << (c % 64)
+		if i > start && isDelimiter(c) {
+			dst = append(dst, i)
+		}
+	}
+	// the keys are compared by the character that follows the path parameter, and the key that ends first precedes.
+	key := func(end int) int {
+		if end == len(path) {
+			return -1
+		}
+		if caseInsensitive {
+			return int(FoldByte(path[end]))
+		}
+		return int(path[end])
+	}
+	for i := n + 1; i < len(dst); i++ {
+		for j := i; j > n && key(dst[j]) < key(dst[j-1]); j-- {
+			dst[j], dst[j-1] = dst[j-1], dst[j]
+		}
+	}
+	return dst
+}
+
+// WildcardEnds appends the ends of the values of a wildcard path parameter that starts at path[start] to dst in the
+// order of the precedence, and returns it.
+// isDelimiter reports whether c is the first character of a literal that follows the wildcard path parameter in a key
+// of the routing table. The value ends at every occurrence of such characters, and they are appended from the last
+// one if shortest is false, otherwise from the first one. The end of path isn't included, the key that ends with
+// the wildcard path parameter must be tried after them. The values are never empty.
+// If caseInsensitive is true, the characters of path are folded by FoldByte before being passed to isDelimiter.
+func WildcardEnds(dst []int, path string, start int, caseInsensitive, shortest bool, isDelimiter func(c byte) bool) []int {
+	n := len(dst)
+	for i := start + 1; i < len(path); i++ {
+		c := path[i]
+		if caseInsensitive {
+			c = FoldByte(c)
+		}
+		if isDelimiter(c) {
+			dst = append(dst, i)
+		}
+	}
+	if !shortest {
+		for i, j := n, len(dst)-1; i < j; i, j = i+1, j-1 {
+			dst[i], dst[j] = dst[j], dst[i]
+		}
+	}
+	return dst
+}
+
+// paramValueEnd returns an index of the end of the value of a path parameter that starts at path[start].
+// delimiter is the first character of the literal that follows the path parameter in the key, or -1 if it is
+// followed by a separator or the end of the key. It returns -1 if the value is empty or isn't followed by delimiter.
+func (s *Syntax) paramValueEnd(path string, start int, delimiter int) int {
+	end := s.NextSeparator(path, start)
+	if delimiter >= 0 {
+		i := strings.IndexByte(path[start:end], byte(delimiter))
+		if i < 0 {
+			return -1
+		}
+		end = start + i
+	}
+	if end == start {
+		return -1
+	}
+	return end
+}
+
+// paramDelimiter returns the first character of the literal that follows the path parameter that ends at key[end],
+// or -1 if the parameter is followed by a separator or the end of key. Any byte including 0 can be a literal.
+func (s *Syntax) paramDelimiter(key string, end int) int {
+	if end < len(key) && !s.isSeparator[key[end]] {
+		return int(key[end])
+	}
+	return -1
+}
+
+// constraintEnd returns an index of the next of the end of the constraint that starts at path[start].
+func constraintEnd(path string, start int) (int, error) {
+	if path[start] == '<' {
+		for i := start + 1; i < len(path); i++ {
+			if path[i] == '>' {
+				return i + 1, nil
+			}
+		}
+		return -1, syntaxError(path, start, "constraint of path parameter isn't closed by '>'")
+	}
+	depth, inClass := 0, false
+	for i := start; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+			if i+1 < len(path) && path[i+1] == '^' {
+				i++
+			}
+			if i+1 < len(path) && path[i+1] == ']' {
+				i++
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return -1, syntaxError(path, start, "constraint of path parameter isn't closed by ')'")
+}
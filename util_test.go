@@ -2,6 +2,7 @@ package urlrouter
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -42,12 +43,15 @@ func Test_IsMetaChar(t *testing.T) {
 
 func Test_ParamNames(t *testing.T) {
 	for path, expected := range map[string][]string{
-		"/:a":    {":a"},
-		"/:b":    {":b"},
-		"/:a/:b": {":a", ":b"},
-		"/:ab":   {":ab"},
-		"/*w":    {"*w"},
-		"/*w/:p": {"*w", ":p"},
+		"/:a":                      {":a"},
+		"/:b":                      {":b"},
+		"/:a/:b":                   {":a", ":b"},
+		"/:ab":                     {":ab"},
+		"/*w":                      {"*w"},
+		"/*w/:p":                   {"*w", ":p"},
+		"/:id<int>/:name([a-z/]+)": {":id", ":name"},
+		"/:a/**/index.html":        {":a", "*1"},
+		"/:/:b/*":                  {":0", ":b", "*2"},
 	} {
 		actual := ParamNames(path)
 		if !reflect.DeepEqual(actual, expected) {
@@ -55,3 +59,78 @@ func Test_ParamNames(t *testing.T) {
 		}
 	}
 }
+
+func Test_ParseParam(t *testing.T) {
+	for _, testcase := range []struct {
+		path       string
+		start      int
+		name       string
+		constraint string
+		end        int
+	}{
+		{"/:id", 1, "id", "", 4},
+		{"/:id/edit", 1, "id", "", 4},
+		{"/:name.:ext", 1, "name", "", 6},
+		{"/:name.:ext", 7, "ext", "", 11},
+		{"/:id<int>/edit", 1, "id", "<int>", 9},
+		{"/:name([a-z.]+)/edit", 1, "name", "([a-z.]+)", 15},
+		{"/:name((a|b)/c)", 1, "name", "((a|b)/c)", 15},
+		{`/:name([)\]]\))`, 1, "name", `([)\]]\))`, 15},
+		{"/:name([^)])", 1, "name", "([^)])", 12},
+		{"/*path", 1, "path", "", 6},
+		{"/*path/:id", 1, "path", "", 6},
+		{"/*path.html", 1, "path", "", 6},
+		{"/*path-:id", 1, "path", "", 6},
+		{"/:from-:to", 1, "from", "", 6},
+		{"/v:version/x", 2, "version", "", 10},
+		{"/img_:id.png", 5, "id", "", 8},
+		{"/:id<int>a", 1, "id", "<int>", 9},
+		{"/:id([0-9]+)a", 1, "id", "([0-9]+)", 12},
+	} {
+		name, constraint, end, err := ParseParam(testcase.path, testcase.start)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", testcase.path, err)
+			continue
+		}
+		actual := []interface{}{name, constraint, end}
+		expected := []interface{}{testcase.name, testcase.constraint, testcase.end}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%q expects %v, but %v", testcase.path, expected, actual)
+		}
+	}
+
+	for _, path := range []string{"/:id<int", "/:id(a", "/:id((a)", "/:id([)", "/:", "/:/a", "/:<int>", "/*", "/:a:b", "/:a*b", "/:id<int>:b", "/:a\u00e9", "/*p<int>", "/*p(a)", "/*a*b", "/*a:b", "/:user-id", "/:user-id/edit", "/:a~b.c", "/*path-x/y", "/**b", "/***"} {
+		if _, _, _, err := ParseParam(path, 1); err == nil {
+			t.Errorf("%q expects error, but nil", path)
+		}
+	}
+}
+
+func Test_ParamEnds(t *testing.T) {
+	delimiters := func(cs string) func(c byte) bool {
+		return func(c byte) bool {
+			return strings.IndexByte(cs, c) >= 0
+		}
+	}
+	for _, testcase := range []struct {
+		path            string
+		start           int
+		caseInsensitive bool
+		delimiters      string
+		expected        []int
+	}{
+		{"/a-b-c", 1, false, "", []int{6}},
+		{"/a-b-c", 1, false, "-", []int{6, 2}},
+		{"/a-b-c/d", 1, false, "-", []int{2, 6}},
+		{"/a~b-c/d", 1, false, "-~", []int{4, 6, 2}},
+		{"/a-b.c", 1, false, "-", []int{2, 4}},
+		{"/-b", 1, false, "-", []int{3}},
+		{"/aXb", 1, true, "x", []int{4, 2}},
+		{"//", 1, false, "-", nil},
+	} {
+		actual := ParamEnds(nil, testcase.path, testcase.start, testcase.caseInsensitive, delimiters(testcase.delimiters))
+		if !reflect.DeepEqual(actual, testcase.expected) {
+			t.Errorf("%q expects %v, but %v", testcase.path, testcase.expected, actual)
+		}
+	}
+}
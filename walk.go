@@ -0,0 +1,86 @@
+package urlrouter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WalkFunc is the type of the function called for each route by Walk.
+// If it returns an error, the walk will be stopped and Walk returns the error.
+type WalkFunc func(key string, value interface{}) error
+
+// Walker is an interface that may be implemented by a URLRouter to enumerate the routes of the built table.
+type Walker interface {
+	// Walk calls fn for each route of the built routing table.
+	// key is a key of the routing table, that is, the keys that have optional segments are passed after
+	// expanding by ExpandOptional, and the keys are normalized by Options if any.
+	Walk(fn func(key string, value interface{}) error) error
+}
+
+// Walk calls fn for each route of router.
+// It returns an error if router doesn't implement Walker.
+func Walk(router URLRouter, fn WalkFunc) error {
+	w, ok := router.(Walker)
+	if !ok {
+		return fmt.Errorf("%T doesn't support walk", router)
+	}
+	return w.Walk(fn)
+}
+
+// DumpFormat represents a format of Dump.
+type DumpFormat int
+
+const (
+	// DumpText is a human readable text format.
+	DumpText DumpFormat = iota
+
+	// DumpDOT is the DOT language of Graphviz.
+	DumpDOT
+)
+
+var dumpFormatNames = map[DumpFormat]string{
+	DumpText: "text",
+	DumpDOT:  "dot",
+}
+
+func (f DumpFormat) String() string {
+	if name, exists := dumpFormatNames[f]; exists {
+		return name
+	}
+	return fmt.Sprintf("DumpFormat(%d)", int(f))
+}
+
+// Dumper is an interface that may be implemented by a URLRouter to dump the internal structure of the built table.
+type Dumper interface {
+	// Dump writes the internal structure of the built routing table to w in format.
+	Dump(w io.Writer, format DumpFormat) error
+}
+
+// Dump writes the internal structure of router to w in format.
+// It returns an error if router doesn't implement Dumper.
+func Dump(w io.Writer, router URLRouter, format DumpFormat) error {
+	d, ok := router.(Dumper)
+	if !ok {
+		return fmt.Errorf("%T doesn't support dump", router)
+	}
+	return d.Dump(w, format)
+}
+
+// JoinKey returns a key that consists of statics and params.
+// params are the path parameters such as ":<int>" and "*" without names, and they are placed between statics.
+// names are the names of params that inserted next to the meta characters.
+// len(statics) must be len(params)+1.
+func JoinKey(statics, params, names []string) string {
+	var buf strings.Builder
+	buf.WriteString(statics[0])
+	for i, param := range params {
+		buf.WriteString(param[:1])
+		if i < len(names) {
+			buf.WriteString(names[i])
+		}
+		buf.WriteString(param[1:])
+		buf.WriteString(statics[i+1])
+	}
+	return buf.String()
+}
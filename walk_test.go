@@ -0,0 +1,46 @@
+package urlrouter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_Walk_withUnsupportedRouter(t *testing.T) {
+	r := &staticURLRouter{}
+	if err := Walk(r, func(key string, value interface{}) error { return nil }); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+	if err := Dump(&bytes.Buffer{}, r, DumpText); err == nil {
+		t.Errorf("Expect error, but nil")
+	}
+}
+
+func Test_JoinKey(t *testing.T) {
+	for _, testcase := range []struct {
+		statics, params, names []string
+		expected               string
+	}{
+		{[]string{"/path/to/route"}, nil, nil, "/path/to/route"},
+		{[]string{"/user/", ""}, []string{":"}, []string{"id"}, "/user/:id"},
+		{[]string{"/user/", "/edit"}, []string{":<int>"}, []string{"id"}, "/user/:id<int>/edit"},
+		{[]string{"/files/", ".", ""}, []string{":([a-z]+)", ":"}, []string{"name", "ext"}, "/files/:name([a-z]+).:ext"},
+		{[]string{"/static/", ""}, []string{"*"}, []string{"filepath"}, "/static/*filepath"},
+	} {
+		if actual := JoinKey(testcase.statics, testcase.params, testcase.names); actual != testcase.expected {
+			t.Errorf("JoinKey(%q, %q, %q) expects %q, but %q", testcase.statics, testcase.params, testcase.names, testcase.expected, actual)
+		}
+	}
+}
+
+func Test_DumpFormat_String(t *testing.T) {
+	for _, testcase := range []struct {
+		format   DumpFormat
+		expected string
+	}{
+		{DumpText, "text"}, {DumpDOT, "dot"}, {DumpFormat(-1), "DumpFormat(-1)"},
+	} {
+		if actual := testcase.format.String(); actual != testcase.expected {
+			t.Errorf("Expect %q, but %q", testcase.expected, actual)
+		}
+	}
+}